@@ -0,0 +1,87 @@
+package pagser
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// baseURLKey the context key of base url
+type baseURLKey struct{}
+
+// WithBaseURL returns the context with the document url, absHref(), absSrc() and absAttr(name)
+// resolve the links against it and `<base href>` of the document.
+func WithBaseURL(ctx context.Context, baseURL *url.URL) context.Context {
+	return context.WithValue(ctx, baseURLKey{}, baseURL)
+}
+
+// BaseURL returns the base url of context, or nil
+func BaseURL(ctx context.Context) *url.URL {
+	baseURL, _ := ctx.Value(baseURLKey{}).(*url.URL)
+	return baseURL
+}
+
+// withDocumentBaseURL returns the context with the base url resolved by `<base href>` of the document,
+// so the document is searched once for each parse.
+func withDocumentBaseURL(ctx context.Context, selection *goquery.Selection) context.Context {
+	baseURL := documentBaseURL(BaseURL(ctx), selection)
+	if baseURL == nil || baseURL == BaseURL(ctx) {
+		return ctx
+	}
+	return WithBaseURL(ctx, baseURL)
+}
+
+// documentBaseURL resolve `<base href>` of the document against the document url, it returns the document url
+// if the document has no valid `<base href>`.
+func documentBaseURL(docURL *url.URL, selection *goquery.Selection) *url.URL {
+	if selection == nil || len(selection.Nodes) == 0 {
+		return docURL
+	}
+	root := selection.Nodes[0]
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	href, ok := goquery.NewDocumentFromNode(root).Find("base[href]").First().Attr("href")
+	if !ok {
+		return docURL
+	}
+	baseURL, err := url.Parse(href)
+	if err != nil {
+		return docURL
+	}
+	if docURL != nil {
+		return docURL.ResolveReference(baseURL)
+	}
+	if baseURL.IsAbs() {
+		return baseURL
+	}
+	return nil
+}
+
+// absAttr convert the attribute value of node to absolute url, the base url is the first argument,
+// or the base url of context, or `<base href>` of the document.
+func absAttr(ctx context.Context, node *goquery.Selection, name string, args ...string) (*url.URL, error) {
+	var baseURL *url.URL
+	if len(args) > 0 {
+		var err error
+		baseURL, err = url.Parse(args[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid base url: %v error: %v", args[0], err)
+		}
+	} else if baseURL = BaseURL(ctx); baseURL == nil {
+		baseURL = documentBaseURL(nil, node)
+	}
+	attrURL, err := url.Parse(node.AttrOr(name, ""))
+	if err != nil {
+		return nil, err
+	}
+	if baseURL == nil {
+		if attrURL.IsAbs() {
+			return attrURL, nil
+		}
+		return nil, fmt.Errorf("no base url to resolve `%v`, set the base url argument, document url or <base href>", attrURL)
+	}
+	return baseURL.ResolveReference(attrURL), nil
+}
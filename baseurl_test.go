@@ -0,0 +1,140 @@
+package pagser
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+type BaseURLData struct {
+	Link    string `pagser:"a.link->absHref()"`
+	Image   string `pagser:"img->absSrc()"`
+	Lazy    string `pagser:"img->absAttr(data-src)"`
+	Fixed   string `pagser:"a.link->absHref('https://fixed.com/')"`
+	Outside string `pagser:"a.outside->absHref()"`
+}
+
+const rawBaseURLHtml = `<html><head>%v</head><body>
+<a class="link" href="next?page=2">next</a>
+<img src="/logo.png" data-src="images/lazy.png">
+<a class="outside" href="https://other.com/x">x</a>
+</body></html>`
+
+func baseURLHtml(head string) string {
+	return strings.Replace(rawBaseURLHtml, "%v", head, 1)
+}
+
+func TestParseWithBaseURL(t *testing.T) {
+	p := New()
+
+	tests := []struct {
+		name string
+		head string
+		base string
+		want BaseURLData
+	}{
+		{
+			name: "document url",
+			base: "https://example.com/list/index.html",
+			want: BaseURLData{
+				Link:    "https://example.com/list/next?page=2",
+				Image:   "https://example.com/logo.png",
+				Lazy:    "https://example.com/list/images/lazy.png",
+				Fixed:   "https://fixed.com/next?page=2",
+				Outside: "https://other.com/x",
+			},
+		},
+		{
+			name: "relative base href",
+			head: `<base href="/static/">`,
+			base: "https://example.com/list/index.html",
+			want: BaseURLData{
+				Link:    "https://example.com/static/next?page=2",
+				Image:   "https://example.com/logo.png",
+				Lazy:    "https://example.com/static/images/lazy.png",
+				Fixed:   "https://fixed.com/next?page=2",
+				Outside: "https://other.com/x",
+			},
+		},
+		{
+			name: "absolute base href without document url",
+			head: `<base href="https://cdn.com/a/">`,
+			want: BaseURLData{
+				Link:    "https://cdn.com/a/next?page=2",
+				Image:   "https://cdn.com/logo.png",
+				Lazy:    "https://cdn.com/a/images/lazy.png",
+				Fixed:   "https://fixed.com/next?page=2",
+				Outside: "https://other.com/x",
+			},
+		},
+	}
+	for _, tt := range tests {
+		var data BaseURLData
+		if err := p.ParseWithBaseURL(&data, baseURLHtml(tt.head), tt.base); err != nil {
+			t.Fatalf("%v: %v", tt.name, err)
+		}
+		if data != tt.want {
+			t.Errorf("%v: want %v, but got %v", tt.name, prettyJson(tt.want), prettyJson(data))
+		}
+
+		//BuiltinFunctions without context uses <base href> only
+		if tt.base == "" {
+			link, err := builtinFun.AbsHref(newTewSelection(baseURLHtml(tt.head)).Filter("a.link"))
+			if err != nil || link.(*url.URL).String() != tt.want.Link {
+				t.Errorf("%v: want %v, but got %v %v", tt.name, tt.want.Link, link, err)
+			}
+		}
+	}
+
+	//no base url
+	var data BaseURLData
+	if err := p.Parse(&data, baseURLHtml("")); err == nil {
+		t.Fatal("absHref() without base url must return error")
+	}
+	if err := p.ParseWithBaseURL(&data, baseURLHtml(""), "http://a b.com/"); err == nil {
+		t.Fatal("invalid base url must return error")
+	}
+}
+
+func TestParseResponseBaseURL(t *testing.T) {
+	p := New()
+	reqURL, _ := url.Parse("https://example.com/list/")
+	resp := &http.Response{
+		Header:  http.Header{"Content-Type": []string{"text/html"}},
+		Body:    io.NopCloser(bytes.NewReader([]byte(baseURLHtml("")))),
+		Request: &http.Request{URL: reqURL},
+	}
+	var data BaseURLData
+	if err := p.ParseResponse(&data, resp); err != nil {
+		t.Fatal(err)
+	}
+	if data.Link != "https://example.com/list/next?page=2" {
+		t.Fatalf("unexpected link: %v", data.Link)
+	}
+
+	//document url of goquery
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(baseURLHtml("")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.Url = reqURL
+	if err = p.ParseDocument(&data, doc); err != nil || data.Image != "https://example.com/logo.png" {
+		t.Fatalf("unexpected image: %v %v", data.Image, err)
+	}
+	rules, err := p.ParseWithRules(doc, Rules{"image": {Selector: "img->absSrc()"}})
+	if err != nil || rules["image"].(*url.URL).String() != "https://example.com/logo.png" {
+		t.Fatalf("unexpected rules image: %v %v", rules["image"], err)
+	}
+
+	//context base url
+	ctx := WithBaseURL(context.Background(), reqURL)
+	if BaseURL(ctx) != reqURL || BaseURL(context.Background()) != nil {
+		t.Fatal("unexpected base url of context")
+	}
+}
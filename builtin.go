@@ -1,6 +1,9 @@
 package pagser
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/PuerkitoBio/goquery"
 )
 
@@ -33,10 +36,29 @@ import (
 //		return "Hello", nil
 //	}
 //
+// Struct function arguments can also be typed, tag arguments are converted to string, bool, int, uint and float types:
+//	type PageData struct{
+//	     Text string `pagser:"h1->MyTypedFunc('Hello', 3, true)"`
+//	}
+//
+//	func (pd PageData) MyTypedFunc(node *goquery.Selection, prefix string, count int, upper bool) (out interface{}, err error) {
+//		//Todo
+//		return prefix, nil
+//	}
+//
 // # Lookup function priority order
 //
 //	struct method -> parent method -> ... -> global
 //
+// # Function pipeline
+//
+// Functions can be chained, each function gets the previous function output,
+// a Selection for selection functions, or a value for value functions.
+//
+//	type PageData struct{
+//	     Text string `pagser:"h1->parent()->child('span')->text()->trim()->toLower()"`
+//	}
+//
 // # Implicit convert type
 //
 // 	Automatic type conversion, Output result string convert to int, int64, float64...
@@ -44,15 +66,45 @@ import (
 // CallFunc is a define function interface
 type CallFunc func(node *goquery.Selection, args ...string) (out interface{}, err error)
 
+// CallFuncContext is a define function interface with the context of ParseContext,
+// the context is context.Background() if parse without context.
+//
+//	pagser.RegisterFuncContext("fetchTitle", func(ctx context.Context, node *goquery.Selection, args ...string) (out interface{}, err error) {
+//		req, err := http.NewRequestWithContext(ctx, "GET", node.AttrOr("href", ""), nil)
+//		//Todo
+//	})
+type CallFuncContext func(ctx context.Context, node *goquery.Selection, args ...string) (out interface{}, err error)
+
+// ValueFunc write value function interface, value is the output of the previous function in pipeline.
+//
+//	func MyValueFunc(value interface{}, args ...string) (out interface{}, err error) {
+//		//Todo
+//		return "Hello-" + cast.ToString(value), nil
+//	}
+//
+//	//Register function
+//	pagser.RegisterValueFunc("MyValueFunc", MyValueFunc)
+//
+//	//Use function
+//	type PageData struct{
+//	     Text string `pagser:"h1->text()->MyValueFunc()"`
+//	}
+type ValueFunc func(value interface{}, args ...string) (out interface{}, err error)
+
 //BuiltinFunctions instance
 var builtinFun BuiltinFunctions
 
 //BuiltinSelections instance
 var builtinSel BuiltinSelections
 
+//BuiltinValues instance
+var builtinVal BuiltinValues
+
 //builtin functions
 var builtinFuncs = map[string]CallFunc{
+	"absAttr":       builtinFun.AbsAttr,
 	"absHref":       builtinFun.AbsHref,
+	"absSrc":        builtinFun.AbsSrc,
 	"attr":          builtinFun.Attr,
 	"attrConcat":    builtinFun.AttrConcat,
 	"attrEmpty":     builtinFun.AttrEmpty,
@@ -88,6 +140,36 @@ var builtinFuncs = map[string]CallFunc{
 	"siblings":     builtinSel.Siblings,
 }
 
+//builtin context functions, they replace the functions of the same name in builtinFuncs
+//to resolve links against the base url of parse context
+var builtinContextFuncs = map[string]CallFuncContext{
+	"absHref": func(ctx context.Context, node *goquery.Selection, args ...string) (out interface{}, err error) {
+		return absAttr(ctx, node, "href", args...)
+	},
+	"absSrc": func(ctx context.Context, node *goquery.Selection, args ...string) (out interface{}, err error) {
+		return absAttr(ctx, node, "src", args...)
+	},
+	"absAttr": func(ctx context.Context, node *goquery.Selection, args ...string) (out interface{}, err error) {
+		if len(args) < 1 {
+			return "", fmt.Errorf("absAttr(name) must has name")
+		}
+		return absAttr(ctx, node, args[0], args[1:]...)
+	},
+}
+
+//builtin value functions
+var builtinValueFuncs = map[string]ValueFunc{
+	"join":       builtinVal.Join,
+	"replace":    builtinVal.Replace,
+	"split":      builtinVal.Split,
+	"time":       builtinVal.Time,
+	"toLower":    builtinVal.ToLower,
+	"toUpper":    builtinVal.ToUpper,
+	"trim":       builtinVal.Trim,
+	"trimPrefix": builtinVal.TrimPrefix,
+	"trimSuffix": builtinVal.TrimSuffix,
+}
+
 // RegisterFunc register function for parse result
 //	pagser.RegisterFunc("MyFunc", func(node *goquery.Selection, args ...string) (out interface{}, err error) {
 //		//Todo
@@ -95,4 +177,38 @@ var builtinFuncs = map[string]CallFunc{
 //	})
 func (p *Pagser) RegisterFunc(name string, fn CallFunc) {
 	p.mapFuncs.Store(name, fn)
+	p.replaceBuiltin(name)
+	p.resetPlans()
+}
+
+// RegisterFuncContext register function with the parse context, it replaces the function of the same name
+//	pagser.RegisterFuncContext("MyFunc", func(ctx context.Context, node *goquery.Selection, args ...string) (out interface{}, err error) {
+//		//Todo
+//		return "Hello", nil
+//	})
+func (p *Pagser) RegisterFuncContext(name string, fn CallFuncContext) {
+	p.mapFuncs.Store(name, fn)
+	p.replaceBuiltin(name)
+	p.resetPlans()
+}
+
+// RegisterValueFunc register value function for pipeline output
+//	pagser.RegisterValueFunc("MyValueFunc", func(value interface{}, args ...string) (out interface{}, err error) {
+//		//Todo
+//		return "Hello", nil
+//	})
+func (p *Pagser) RegisterValueFunc(name string, fn ValueFunc) {
+	p.mapValueFuncs.Store(name, fn)
+	p.replaceBuiltin(name)
+	p.resetPlans()
+}
+
+// replaceBuiltin marks the builtin function of name is replaced, the generated Parser calls the builtin functions
+func (p *Pagser) replaceBuiltin(name string) {
+	_, isFunc := builtinFuncs[name]
+	_, isContextFunc := builtinContextFuncs[name]
+	_, isValueFunc := builtinValueFuncs[name]
+	if isFunc || isContextFunc || isValueFunc {
+		p.builtinReplaced.Store(true)
+	}
 }
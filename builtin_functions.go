@@ -1,10 +1,10 @@
 package pagser
 
 import (
+	"context"
 	"fmt"
 	"github.com/PuerkitoBio/goquery"
 	"github.com/spf13/cast"
-	"net/url"
 	"strconv"
 	"strings"
 )
@@ -13,25 +13,39 @@ import (
 type BuiltinFunctions struct {
 }
 
-// AbsHref absHref(baseUrl) get element attribute name `href`, and convert to absolute url, return *URL.
-// `baseUrl` is the base url like `https://example.com/`.
+// AbsHref absHref(baseUrl='') get element attribute name `href`, and convert to absolute url, return *URL.
+// `baseUrl` is the base url like `https://example.com/`, if it is empty, the url of ParseResponse,
+// ParseWithBaseURL or WithBaseURL context and `<base href>` of the document are used.
 //	//<a href="/foolin/pagser">Pagser</a>
 //	struct {
 //		Example string `pagser:".selector->absHref('https://github.com/')"`
+//		Auto    string `pagser:".selector->absHref()"`
 //	}
 func (builtin BuiltinFunctions) AbsHref(selection *goquery.Selection, args ...string) (out interface{}, err error) {
+	return absAttr(context.Background(), selection, "href", args...)
+}
+
+// AbsSrc absSrc(baseUrl='') get element attribute name `src`, and convert to absolute url, return *URL.
+// The base url is the same as absHref.
+//	//<img src="/logo.png">
+//	struct {
+//		Example string `pagser:"img->absSrc()"`
+//	}
+func (builtin BuiltinFunctions) AbsSrc(selection *goquery.Selection, args ...string) (out interface{}, err error) {
+	return absAttr(context.Background(), selection, "src", args...)
+}
+
+// AbsAttr absAttr(name, baseUrl='') get element attribute value, and convert to absolute url, return *URL.
+// The base url is the same as absHref.
+//	//<img data-src="/logo.png">
+//	struct {
+//		Example string `pagser:"img->absAttr(data-src)"`
+//	}
+func (builtin BuiltinFunctions) AbsAttr(selection *goquery.Selection, args ...string) (out interface{}, err error) {
 	if len(args) < 1 {
-		return "", fmt.Errorf("args must has baseUrl")
-	}
-	baseUrl, err := url.Parse(args[0])
-	if err != nil {
-		return "", fmt.Errorf("invalid base url: %v error: %v", baseUrl, err)
-	}
-	hrefUrl, err := url.Parse(selection.AttrOr("href", ""))
-	if err != nil {
-		return "", err
+		return "", fmt.Errorf("absAttr(name) must has name")
 	}
-	return baseUrl.ResolveReference(hrefUrl), nil
+	return absAttr(context.Background(), selection, args[0], args[1:]...)
 }
 
 // Attr attr(name, defaultValue='') get element attribute value, return string.
@@ -0,0 +1,198 @@
+package pagser
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// BuiltinValues builtin value functions are registered with a lowercase initial, eg: ToLower -> toLower()
+//
+// Value functions receive the output of the previous function in the pipeline,
+// if the previous output is a Selection, its trimmed text is used as value.
+//	struct {
+//		Example string `pagser:".selector->text()->trim()->toLower()"`
+//	}
+type BuiltinValues struct {
+}
+
+// Join join(sep=',') join []string value to string, return string.
+//	struct {
+//		Example string `pagser:".selector->eachText()->join('|')"`
+//	}
+func (builtin BuiltinValues) Join(value interface{}, args ...string) (out interface{}, err error) {
+	sep := ","
+	if len(args) > 0 {
+		sep = args[0]
+	}
+	list, err := cast.ToStringSliceE(value)
+	if err != nil {
+		return nil, fmt.Errorf("join(sep) value must be []string: %v", err)
+	}
+	return strings.Join(list, sep), nil
+}
+
+// Replace replace(old, new) replace all `old` to `new` in value, return string or []string.
+//	struct {
+//		Example string `pagser:".selector->text()->replace('$', '')"`
+//	}
+func (builtin BuiltinValues) Replace(value interface{}, args ...string) (out interface{}, err error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("replace(old, new) must has old and new value")
+	}
+	return mapStringValue(value, func(s string) string {
+		return strings.ReplaceAll(s, args[0], args[1])
+	})
+}
+
+// Split split(sep=',', trim='true') split string value by separator, return []string.
+//	struct {
+//		Examples []string `pagser:".selector->attr(content)->split(',')"`
+//	}
+func (builtin BuiltinValues) Split(value interface{}, args ...string) (out interface{}, err error) {
+	sep := ","
+	trim := true
+	if len(args) > 0 {
+		sep = args[0]
+	}
+	if len(args) > 1 {
+		trim, err = cast.ToBoolE(args[1])
+		if err != nil {
+			return nil, fmt.Errorf("`trim` must bool type value: true/false")
+		}
+	}
+	text, err := cast.ToStringE(value)
+	if err != nil {
+		return nil, fmt.Errorf("split(sep) value must be string: %v", err)
+	}
+	list := strings.Split(text, sep)
+	if trim {
+		for i, v := range list {
+			list[i] = strings.TrimSpace(v)
+		}
+	}
+	return list, nil
+}
+
+// Time time(layout='', location='') parse value to time.Time in location, return time.Time or []time.Time.
+// `layout` is the time layout like `2006-01-02`, or `unix`, `unixMilli` for timestamps,
+// if layout is empty, try unix timestamp, relative time (eg: `3 hours ago`) and common layouts.
+// `location` is the IANA time zone name like `Europe/Berlin`, default is UTC.
+//	struct {
+//		Example time.Time `pagser:".selector->attr(datetime)->time('2006-01-02', 'Europe/Berlin')"`
+//	}
+func (builtin BuiltinValues) Time(value interface{}, args ...string) (out interface{}, err error) {
+	layout := ""
+	loc := time.UTC
+	if len(args) > 0 {
+		layout = args[0]
+	}
+	if len(args) > 1 && strings.TrimSpace(args[1]) != "" {
+		loc, err = time.LoadLocation(strings.TrimSpace(args[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid location: %v", err)
+		}
+	}
+	parse := func(v interface{}) (time.Time, error) {
+		if layout == "" {
+			return toTimeE(v, loc)
+		}
+		text, err := cast.ToStringE(v)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return parseTime(text, layout, loc)
+	}
+	if list, ok := value.([]string); ok {
+		times := make([]time.Time, len(list))
+		for i, v := range list {
+			times[i], err = parse(v)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrCast, err)
+			}
+		}
+		return times, nil
+	}
+	t, err := parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCast, err)
+	}
+	return t, nil
+}
+
+// ToLower toLower() convert value to lower case, return string or []string.
+//	struct {
+//		Example string `pagser:".selector->text()->toLower()"`
+//	}
+func (builtin BuiltinValues) ToLower(value interface{}, args ...string) (out interface{}, err error) {
+	return mapStringValue(value, strings.ToLower)
+}
+
+// ToUpper toUpper() convert value to upper case, return string or []string.
+//	struct {
+//		Example string `pagser:".selector->text()->toUpper()"`
+//	}
+func (builtin BuiltinValues) ToUpper(value interface{}, args ...string) (out interface{}, err error) {
+	return mapStringValue(value, strings.ToUpper)
+}
+
+// Trim trim(cutset='') remove leading and trailing white space, or the characters in `cutset` if not empty,
+// return string or []string.
+//	struct {
+//		Example string `pagser:".selector->attr(title)->trim()"`
+//	}
+func (builtin BuiltinValues) Trim(value interface{}, args ...string) (out interface{}, err error) {
+	if len(args) > 0 && args[0] != "" {
+		cutset := args[0]
+		return mapStringValue(value, func(s string) string {
+			return strings.Trim(s, cutset)
+		})
+	}
+	return mapStringValue(value, strings.TrimSpace)
+}
+
+// TrimPrefix trimPrefix(prefix) remove the leading prefix string, return string or []string.
+//	struct {
+//		Example string `pagser:".selector->text()->trimPrefix('Price:')"`
+//	}
+func (builtin BuiltinValues) TrimPrefix(value interface{}, args ...string) (out interface{}, err error) {
+	if len(args) < 1 {
+		return nil, fmt.Errorf("trimPrefix(prefix) must has prefix")
+	}
+	return mapStringValue(value, func(s string) string {
+		return strings.TrimPrefix(s, args[0])
+	})
+}
+
+// TrimSuffix trimSuffix(suffix) remove the trailing suffix string, return string or []string.
+//	struct {
+//		Example string `pagser:".selector->text()->trimSuffix('%')"`
+//	}
+func (builtin BuiltinValues) TrimSuffix(value interface{}, args ...string) (out interface{}, err error) {
+	if len(args) < 1 {
+		return nil, fmt.Errorf("trimSuffix(suffix) must has suffix")
+	}
+	return mapStringValue(value, func(s string) string {
+		return strings.TrimSuffix(s, args[0])
+	})
+}
+
+// mapStringValue apply fn to string value, or each item of []string value
+func mapStringValue(value interface{}, fn func(string) string) (interface{}, error) {
+	switch v := value.(type) {
+	case []string:
+		list := make([]string, len(v))
+		for i, s := range v {
+			list[i] = fn(s)
+		}
+		return list, nil
+	default:
+		text, err := cast.ToStringE(value)
+		if err != nil {
+			return nil, err
+		}
+		return fn(text), nil
+	}
+}
@@ -0,0 +1,52 @@
+package pagser
+
+import (
+	"fmt"
+	"testing"
+)
+
+type valueFuncWantError struct {
+	want  bool
+	fun   string
+	args  []string
+	value interface{}
+}
+
+func (vfwe valueFuncWantError) String() string {
+	return fmt.Sprintf("%v(%v) call `%v`", vfwe.fun, vfwe.args, vfwe.value)
+}
+
+func TestBuiltinValuesErrors(t *testing.T) {
+	tests := []valueFuncWantError{
+		//not []string
+		{true, "join", []string{}, struct{}{}},
+		{false, "join", []string{"|"}, []string{"a", "b"}},
+		//not new value
+		{true, "replace", []string{"a"}, "abc"},
+		{false, "replace", []string{"a", "b"}, "abc"},
+		//trim value '1234' is not bool type
+		{true, "split", []string{"|", "1234"}, "a|b"},
+		//not string
+		{true, "split", []string{}, struct{}{}},
+		{false, "toLower", []string{}, []string{"A", "B"}},
+		{true, "toUpper", []string{}, struct{}{}},
+		{false, "trim", []string{"$"}, "$12$"},
+		//not prefix
+		{true, "trimPrefix", []string{}, "abc"},
+		//not suffix
+		{true, "trimSuffix", []string{}, "abc"},
+	}
+
+	for _, tt := range tests {
+		_, err := builtinValueFuncs[tt.fun](tt.value, tt.args...)
+		if tt.want {
+			if err == nil {
+				t.Errorf("%v want an error", tt.String())
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v want no error, but error is %v", tt.String(), err)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package pagser
+
+import (
+	"bytes"
+	"io"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/transform"
+)
+
+// NewDocument read html to document, the html is transcoded to UTF-8 by the charset of BOM,
+// the Content-Type hint or `<meta charset>`, the contentType can be empty.
+func NewDocument(reader io.Reader, contentType string) (*goquery.Document, error) {
+	reader, err := decodeReader(reader, contentType)
+	if err != nil {
+		return nil, err
+	}
+	return goquery.NewDocumentFromReader(reader)
+}
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// decodeReader read the document and transcode it to UTF-8, the charset is detected from BOM,
+// the Content-Type hint, eg: `text/html; charset=gbk`, or `<meta charset>` of the document.
+// The document is not transcoded if the charset is detected from `<meta charset>` or guessed,
+// but the content is valid UTF-8.
+func decodeReader(reader io.Reader, contentType string) (io.Reader, error) {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	enc, name, certain := charset.DetermineEncoding(content, contentType)
+	if name == "utf-8" || (!certain && utf8.Valid(content)) {
+		return bytes.NewReader(bytes.TrimPrefix(content, utf8BOM)), nil
+	}
+	return transform.NewReader(bytes.NewReader(content), enc.NewDecoder()), nil
+}
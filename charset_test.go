@@ -0,0 +1,93 @@
+package pagser
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+type CharsetData struct {
+	Title string `pagser:"title"`
+	Text  string `pagser:"p"`
+}
+
+func encodeHtml(t *testing.T, enc encoding.Encoding, html string) []byte {
+	data, err := enc.NewEncoder().Bytes([]byte(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestParseReaderCharset(t *testing.T) {
+	p := New()
+
+	tests := []struct {
+		name        string
+		content     []byte
+		contentType string
+		want        CharsetData
+	}{
+		{
+			name:    "meta gbk",
+			content: encodeHtml(t, simplifiedchinese.GBK, `<html><head><meta charset="gbk"><title>标题</title></head><body><p>你好，世界</p></body></html>`),
+			want:    CharsetData{Title: "标题", Text: "你好，世界"},
+		},
+		{
+			name:    "meta http-equiv windows-1251",
+			content: encodeHtml(t, charmap.Windows1251, `<html><head><meta http-equiv="Content-Type" content="text/html; charset=windows-1251"><title>Заголовок</title></head><body><p>Привет</p></body></html>`),
+			want:    CharsetData{Title: "Заголовок", Text: "Привет"},
+		},
+		{
+			name:        "content type shift_jis",
+			content:     encodeHtml(t, japanese.ShiftJIS, `<html><head><title>タイトル</title></head><body><p>こんにちは</p></body></html>`),
+			contentType: "text/html; charset=Shift_JIS",
+			want:        CharsetData{Title: "タイトル", Text: "こんにちは"},
+		},
+		{
+			name:    "utf-8 bom",
+			content: append([]byte{0xEF, 0xBB, 0xBF}, `<html><head><meta charset="gbk"><title>标题</title></head><body><p>你好</p></body></html>`...),
+			want:    CharsetData{Title: "标题", Text: "你好"},
+		},
+		{
+			name:    "utf-8 without meta",
+			content: []byte(`<html><head><title>Title</title></head><body>` + strings.Repeat(" ", 2048) + `<p>你好</p></body></html>`),
+			want:    CharsetData{Title: "Title", Text: "你好"},
+		},
+	}
+	for _, tt := range tests {
+		var data CharsetData
+		if err := p.ParseReaderContentType(&data, bytes.NewReader(tt.content), tt.contentType); err != nil {
+			t.Fatalf("%v: %v", tt.name, err)
+		}
+		if data != tt.want {
+			t.Errorf("%v: want %v, but got %v", tt.name, tt.want, data)
+		}
+	}
+}
+
+func TestParseResponse(t *testing.T) {
+	p := New()
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"text/html; charset=gbk"}},
+		Body:       io.NopCloser(bytes.NewReader(encodeHtml(t, simplifiedchinese.GBK, `<title>标题</title><p>你好</p>`))),
+	}
+	var data CharsetData
+	if err := p.ParseResponse(&data, resp); err != nil {
+		t.Fatal(err)
+	}
+	if want := (CharsetData{Title: "标题", Text: "你好"}); data != want {
+		t.Fatalf("want %v, but got %v", want, data)
+	}
+	if err := p.ParseResponse(&data, nil); err == nil {
+		t.Fatal("nil response must return error")
+	}
+}
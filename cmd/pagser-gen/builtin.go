@@ -0,0 +1,74 @@
+package main
+
+// builtinFuncs the builtin functions called with the selection, name => go expression
+var builtinFuncs = map[string]string{
+	"absAttr":       "pagser.BuiltinFunctions{}.AbsAttr",
+	"absHref":       "pagser.BuiltinFunctions{}.AbsHref",
+	"absSrc":        "pagser.BuiltinFunctions{}.AbsSrc",
+	"attr":          "pagser.BuiltinFunctions{}.Attr",
+	"attrConcat":    "pagser.BuiltinFunctions{}.AttrConcat",
+	"attrEmpty":     "pagser.BuiltinFunctions{}.AttrEmpty",
+	"attrSplit":     "pagser.BuiltinFunctions{}.AttrSplit",
+	"eachAttr":      "pagser.BuiltinFunctions{}.EachAttr",
+	"eachAttrEmpty": "pagser.BuiltinFunctions{}.EachAttrEmpty",
+	"eachHtml":      "pagser.BuiltinFunctions{}.EachHtml",
+	"eachOutHtml":   "pagser.BuiltinFunctions{}.EachOutHtml",
+	"eachText":      "pagser.BuiltinFunctions{}.EachText",
+	"eachTextEmpty": "pagser.BuiltinFunctions{}.EachTextEmpty",
+	"eachTextJoin":  "pagser.BuiltinFunctions{}.EachTextJoin",
+	"eqAndAttr":     "pagser.BuiltinFunctions{}.EqAndAttr",
+	"eqAndHtml":     "pagser.BuiltinFunctions{}.EqAndHtml",
+	"eqAndOutHtml":  "pagser.BuiltinFunctions{}.EqAndOutHtml",
+	"eqAndText":     "pagser.BuiltinFunctions{}.EqAndText",
+	"html":          "pagser.BuiltinFunctions{}.Html",
+	"outerHtml":     "pagser.BuiltinFunctions{}.OutHtml",
+	"size":          "pagser.BuiltinFunctions{}.Size",
+	"text":          "pagser.BuiltinFunctions{}.Text",
+	"textConcat":    "pagser.BuiltinFunctions{}.TextConcat",
+	"textEmpty":     "pagser.BuiltinFunctions{}.TextEmpty",
+	"textSplit":     "pagser.BuiltinFunctions{}.TextSplit",
+	// selector
+	"child":        "pagser.BuiltinSelections{}.Child",
+	"eq":           "pagser.BuiltinSelections{}.Eq",
+	"first":        "pagser.BuiltinSelections{}.First",
+	"last":         "pagser.BuiltinSelections{}.Last",
+	"next":         "pagser.BuiltinSelections{}.Next",
+	"parent":       "pagser.BuiltinSelections{}.Parent",
+	"parents":      "pagser.BuiltinSelections{}.Parents",
+	"parentsUntil": "pagser.BuiltinSelections{}.ParentsUntil",
+	"prev":         "pagser.BuiltinSelections{}.Prev",
+	"siblings":     "pagser.BuiltinSelections{}.Siblings",
+}
+
+// builtinValueFuncs the builtin value functions called with the previous output, name => go expression
+var builtinValueFuncs = map[string]string{
+	"join":       "pagser.BuiltinValues{}.Join",
+	"replace":    "pagser.BuiltinValues{}.Replace",
+	"split":      "pagser.BuiltinValues{}.Split",
+	"time":       "pagser.BuiltinValues{}.Time",
+	"toLower":    "pagser.BuiltinValues{}.ToLower",
+	"toUpper":    "pagser.BuiltinValues{}.ToUpper",
+	"trim":       "pagser.BuiltinValues{}.Trim",
+	"trimPrefix": "pagser.BuiltinValues{}.TrimPrefix",
+	"trimSuffix": "pagser.BuiltinValues{}.TrimSuffix",
+}
+
+// castFuncs the cast function of basic types, type => cast function name without `To` prefix
+var castFuncs = map[string]string{
+	"bool":    "Bool",
+	"string":  "String",
+	"int":     "Int",
+	"int8":    "Int8",
+	"int16":   "Int16",
+	"int32":   "Int32",
+	"int64":   "Int64",
+	"uint":    "Uint",
+	"uint8":   "Uint8",
+	"uint16":  "Uint16",
+	"uint32":  "Uint32",
+	"uint64":  "Uint64",
+	"float32": "Float32",
+	"float64": "Float64",
+	"byte":    "Uint8",
+	"rune":    "Int32",
+}
@@ -0,0 +1,737 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+	"github.com/foolin/pagser"
+)
+
+// typeKind the kind of field type for code generation
+type typeKind int
+
+const (
+	kindBasic       typeKind = iota //bool, string, numbers, or named type of them
+	kindAny                         //interface{}
+	kindTime                        //time.Time
+	kindText                        //implements encoding.TextUnmarshaler
+	kindUnmarshaler                 //implements pagser.Unmarshaler
+	kindStruct                      //named struct, parsed by the generated ParsePagser
+	kindAnonStruct                  //anonymous struct, parsed inline
+	kindPtr
+	kindSlice
+)
+
+// typeInfo the resolved field type
+type typeInfo struct {
+	kind   typeKind
+	expr   string          //go source of type, eg: `[]Item`
+	basic  string          //basic type name of kindBasic
+	named  bool            //the kindBasic type is a named type, needs conversion
+	fields *ast.StructType //fields of kindAnonStruct
+	elem   *typeInfo       //element of kindPtr and kindSlice
+}
+
+// isNode the type is parsed from a Selection
+func (t *typeInfo) isNode() bool {
+	switch t.kind {
+	case kindUnmarshaler, kindStruct, kindAnonStruct:
+		return true
+	case kindPtr, kindSlice:
+		return t.elem.isNode()
+	}
+	return false
+}
+
+// generator generate the ParsePagser methods of the structs in a package
+type generator struct {
+	pagser  *pagser.Pagser
+	tagName string
+	fset    *token.FileSet
+	sources map[string][]byte                   //file name => source
+	types   map[string]*ast.TypeSpec            //type name => type spec
+	methods map[string]map[string]*ast.FuncType //type name => method name => func type
+
+	buf       *bytes.Buffer
+	imports   map[string]bool
+	matchers  []string //selectors of current type
+	recvType  string   //current struct type
+	varSeq    int      //sequence of local variable names
+	usesErr   bool     //the err variable of method is used
+	generated map[string]bool
+	queue     []string
+}
+
+func newGenerator(p *pagser.Pagser) *generator {
+	return &generator{
+		pagser:    p,
+		buf:       &bytes.Buffer{},
+		tagName:   p.Config.TagName,
+		fset:      token.NewFileSet(),
+		sources:   make(map[string][]byte),
+		types:     make(map[string]*ast.TypeSpec),
+		methods:   make(map[string]map[string]*ast.FuncType),
+		imports:   make(map[string]bool),
+		generated: make(map[string]bool),
+	}
+}
+
+// addFile collect the type specs and methods of file
+func (g *generator) addFile(file *ast.File) {
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				if typeSpec, ok := spec.(*ast.TypeSpec); ok {
+					g.types[typeSpec.Name.Name] = typeSpec
+				}
+			}
+		case *ast.FuncDecl:
+			if d.Recv == nil || len(d.Recv.List) == 0 {
+				continue
+			}
+			recv := d.Recv.List[0].Type
+			if star, ok := recv.(*ast.StarExpr); ok {
+				recv = star.X
+			}
+			ident, ok := recv.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			if g.methods[ident.Name] == nil {
+				g.methods[ident.Name] = make(map[string]*ast.FuncType)
+			}
+			g.methods[ident.Name][d.Name.Name] = d.Type
+		}
+	}
+}
+
+// taggedTypes returns the struct types with pagser tags of the files, or all files if fileName is empty
+func (g *generator) taggedTypes(fileName string) []string {
+	names := make([]string, 0)
+	for name, spec := range g.types {
+		if g.hasTaggedFields(spec) && (fileName == "" || g.fset.Position(spec.Pos()).Filename == fileName) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// hasTaggedFields the type is a struct with pagser tags
+func (g *generator) hasTaggedFields(spec *ast.TypeSpec) bool {
+	structType, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return false
+	}
+	for _, field := range structType.Fields.List {
+		if _, ok := g.fieldTag(field, g.tagName); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// generate the ParsePagser methods of types, and the nested struct types
+func (g *generator) generate(pkgName string, typeNames []string) ([]byte, error) {
+	g.queue = append(g.queue, typeNames...)
+	body := bytes.Buffer{}
+	for len(g.queue) > 0 {
+		name := g.queue[0]
+		g.queue = g.queue[1:]
+		if g.generated[name] {
+			continue
+		}
+		g.generated[name] = true
+		if err := g.generateType(name); err != nil {
+			return nil, err
+		}
+		body.Write(g.buf.Bytes())
+		g.buf.Reset()
+	}
+
+	out := bytes.Buffer{}
+	fmt.Fprintf(&out, "// Code generated by pagser-gen. DO NOT EDIT.\n\npackage %v\n\nimport (\n", pkgName)
+	imports := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		imports = append(imports, path)
+	}
+	sort.Slice(imports, func(i, j int) bool {
+		//standard packages first
+		stdI, stdJ := !strings.Contains(imports[i], "."), !strings.Contains(imports[j], ".")
+		if stdI != stdJ {
+			return stdI
+		}
+		return imports[i] < imports[j]
+	})
+	for i, path := range imports {
+		if i > 0 && !strings.Contains(imports[i-1], ".") && strings.Contains(path, ".") {
+			out.WriteString("\n")
+		}
+		fmt.Fprintf(&out, "\t%q\n", path)
+	}
+	out.WriteString(")\n")
+	out.Write(body.Bytes())
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code error: %v\n%s", err, out.Bytes())
+	}
+	return src, nil
+}
+
+func (g *generator) generateType(name string) error {
+	spec, ok := g.types[name]
+	if !ok {
+		return fmt.Errorf("type %v not found", name)
+	}
+	structType, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return fmt.Errorf("type %v is not a struct", name)
+	}
+	g.recvType = name
+	g.matchers = nil
+	g.varSeq = 0
+	g.usesErr = false
+	g.imports["github.com/PuerkitoBio/goquery"] = true
+
+	buf := g.buf
+	g.buf = &bytes.Buffer{}
+	err := g.genFields(structType, "v", "sel", "")
+	fields := g.buf
+	g.buf = buf
+	if err != nil {
+		return fmt.Errorf("type %v %v", name, err)
+	}
+
+	g.printf("\n// ParsePagser parse the selection to %v, it is generated from the struct tags.\n", name)
+	g.printf("func (v *%v) ParsePagser(sel *goquery.Selection) error {\n", name)
+	if fields.Len() > 0 {
+		g.printf("var out interface{}\n")
+		if g.usesErr {
+			g.printf("var err error\n")
+		}
+		g.buf.Write(fields.Bytes())
+	}
+	g.printf("return nil\n}\n")
+	g.printf("\n// PagserTags returns the tag name and function symbol of the struct tags ParsePagser is generated from.\n")
+	g.printf("func (v *%v) PagserTags() (string, string) {\nreturn %q, %q\n}\n", name, g.tagName, g.pagser.Config.FuncSymbol)
+	if len(g.matchers) > 0 {
+		g.imports["github.com/andybalholm/cascadia"] = true
+		g.printf("\nvar pagser%vMatchers = []goquery.Matcher{\n", name)
+		for _, selector := range g.matchers {
+			g.printf("cascadia.MustCompile(%q),\n", selector)
+		}
+		g.printf("}\n")
+	}
+	return nil
+}
+
+// genFields generate the code of tagged fields, obj is the struct pointer, sel is the selection,
+// and path is the go expression of struct path.
+func (g *generator) genFields(structType *ast.StructType, obj string, sel string, path string) error {
+	for _, field := range structType.Fields.List {
+		tagValue, ok := g.fieldTag(field, g.tagName)
+		if !ok || tagValue == "-" {
+			continue
+		}
+		names := make([]string, 0, len(field.Names))
+		for _, ident := range field.Names {
+			names = append(names, ident.Name)
+		}
+		if len(names) == 0 {
+			//embedded field
+			names = append(names, embeddedName(field.Type))
+		}
+		for _, name := range names {
+			if err := g.genField(field, name, tagValue, obj, sel, joinPath(path, name)); err != nil {
+				return fmt.Errorf("field %v: %v", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (g *generator) genField(field *ast.Field, name string, tagValue string, obj string, sel string, path string) error {
+	if _, ok := g.fieldTag(field, g.tagName+"_opts"); ok {
+		return fmt.Errorf("tag options are not supported")
+	}
+	ti, err := g.resolveType(field.Type)
+	if err != nil {
+		return err
+	}
+	tag, err := g.pagser.ParseTag(tagValue)
+	if err != nil {
+		return err
+	}
+	fieldErr := fmt.Sprintf("%v, %q, %q, %q", path, tag.Value, tag.Selector, funcsString(tag))
+
+	g.printf("\n// %v `%v`\n", name, tagValue)
+	if err := g.genAlternative(tag, sel); err != nil {
+		return err
+	}
+	for _, fallback := range tag.Fallbacks {
+		g.printf("if err == nil && pagser.NeedFallback(out) {\n")
+		if err := g.genAlternative(fallback, sel); err != nil {
+			return err
+		}
+		g.printf("}\n")
+	}
+	g.imports["github.com/foolin/pagser"] = true
+	if hasFuncs(tag) {
+		g.printf("if err != nil {\nreturn pagser.NewFieldError(%v, err)\n}\n", fieldErr)
+	}
+	if obj == "v" && (hasFuncs(tag) || len(tag.Fallbacks) > 0) {
+		g.usesErr = true
+	}
+	return g.genAssign(ti, obj+"."+name, "out", path, fieldErr)
+}
+
+// genAlternative generate the selector and functions pipeline of tag alternative
+func (g *generator) genAlternative(tag *pagser.TagInfo, sel string) error {
+	switch {
+	case strings.HasPrefix(tag.Selector, "xpath:"):
+		return fmt.Errorf("xpath selector `%v` is not supported", tag.Selector)
+	case tag.Selector == "":
+		g.printf("out = %v\n", sel)
+	default:
+		if _, err := cascadia.Compile(tag.Selector); err != nil {
+			return fmt.Errorf("invalid selector `%v`: %v", tag.Selector, err)
+		}
+		g.printf("out = %v.FindMatcher(pagser%vMatchers[%v])\n", sel, g.recvType, g.matcherIndex(tag.Selector))
+	}
+	for _, fn := range tag.Funcs {
+		if err := g.genFunc(fn); err != nil {
+			return err
+		}
+	}
+	if len(tag.Funcs) > 0 {
+		g.imports["errors"] = true
+		g.printf("if errors.Is(err, pagser.ErrCast) {\nout, err = nil, nil\n}\n")
+	}
+	return nil
+}
+
+// genFunc generate the function call, the order is struct method, builtin function and builtin value function
+func (g *generator) genFunc(fn *pagser.TagFunc) error {
+	if method, ok := g.methods[g.recvType][fn.Name]; ok {
+		args, err := methodArgs(method, fn)
+		if err != nil {
+			return err
+		}
+		returns := "out, err"
+		if method.Results.NumFields() == 1 {
+			returns = "out"
+		}
+		g.printf("if err == nil {\nvar node *goquery.Selection\n")
+		g.printf("if node, err = pagser.OutputNode(out, %q); err == nil {\n%v = v.%v(node%v)\n}\n}\n", fn.Name, returns, fn.Name, args)
+		return nil
+	}
+	args := quoteArgs(fn.Params)
+	if call, ok := builtinFuncs[fn.Name]; ok {
+		g.printf("if err == nil {\nvar node *goquery.Selection\n")
+		g.printf("if node, err = pagser.OutputNode(out, %q); err == nil {\nout, err = %v(node%v)\n}\n}\n", fn.Name, call, args)
+		return nil
+	}
+	if call, ok := builtinValueFuncs[fn.Name]; ok {
+		g.printf("if err == nil {\nout, err = %v(pagser.OutputValue(out)%v)\n}\n", call, args)
+		return nil
+	}
+	if owners := g.methodOwners(fn.Name); len(owners) > 0 {
+		return fmt.Errorf("function `%v` is not a method of %v but %v, the generated ParsePagser of nested struct "+
+			"can not call the methods of parent struct, move the method to %v", fn.Name, g.recvType, strings.Join(owners, ", "), g.recvType)
+	}
+	return fmt.Errorf("function `%v` is not a method of %v or a builtin function", fn.Name, g.recvType)
+}
+
+// methodOwners returns the names of types have the method
+func (g *generator) methodOwners(name string) []string {
+	owners := make([]string, 0)
+	for typeName, methods := range g.methods {
+		if _, ok := methods[name]; ok {
+			owners = append(owners, typeName)
+		}
+	}
+	sort.Strings(owners)
+	return owners
+}
+
+// genAssign generate the code to set target by the output value
+func (g *generator) genAssign(ti *typeInfo, target string, value string, path string, fieldErr string) error {
+	fail := func(err string) string {
+		return fmt.Sprintf("return pagser.NewFieldError(%v, %v)\n", fieldErr, err)
+	}
+	if ti.isNode() {
+		node := g.newVar("node")
+		g.printf("{\n%v, err := pagser.OutputNode(%v, %q)\nif err != nil {\n%v}\n", node, value, "", fail("err"))
+		if err := g.genNode(ti, target, node, path, fieldErr); err != nil {
+			return err
+		}
+		g.printf("}\n")
+		return nil
+	}
+	switch ti.kind {
+	case kindBasic:
+		g.imports["github.com/spf13/cast"] = true
+		cast := fmt.Sprintf("cast.To%v", castFuncs[ti.basic])
+		g.printf("%v = %v\n", target, ti.convert(fmt.Sprintf("%v(pagser.OutputValue(%v))", cast, value)))
+	case kindAny:
+		g.printf("%v = %v\n", target, value)
+	case kindTime, kindPtr:
+		if ti.kind == kindPtr && ti.elem.kind == kindText {
+			elem := g.newVar("elem")
+			g.printf("if %v != nil {\n%v := new(%v)\n", value, elem, ti.elem.expr)
+			g.genText(elem, value, fail)
+			g.printf("%v = %v\n}\n", target, elem)
+			return nil
+		}
+		if ti.kind == kindPtr && ti.elem.kind != kindTime {
+			return fmt.Errorf("pointer type %v is not supported", ti.expr)
+		}
+		result := "value"
+		if ti.kind == kindPtr {
+			result = "&value"
+		}
+		g.printf("if value, err := pagser.ToTimeE(pagser.OutputValue(%v)); err == nil {\n%v = %v\n}\n", value, target, result)
+	case kindText:
+		g.printf("if %v != nil {\n", value)
+		g.genText(target, value, fail)
+		g.printf("}\n")
+	case kindSlice:
+		items, index := g.newVar("items"), g.newVar("i")
+		g.printf("{\n%v := pagser.OutputList(%v)\n%v = make(%v, len(%v))\nfor %v := range %v {\n",
+			items, value, target, ti.expr, items, index, items)
+		itemPath := g.indexPath(path, index)
+		itemErr := strings.Replace(fieldErr, path, itemPath, 1)
+		if err := g.genAssign(ti.elem, fmt.Sprintf("%v[%v]", target, index), fmt.Sprintf("%v[%v]", items, index), itemPath, itemErr); err != nil {
+			return err
+		}
+		g.printf("}\n}\n")
+	default:
+		return fmt.Errorf("type %v is not supported", ti.expr)
+	}
+	return nil
+}
+
+// genText generate the code to unmarshal text to target
+func (g *generator) genText(target string, value string, fail func(err string) string) {
+	g.imports["github.com/spf13/cast"] = true
+	g.printf("if err := %v.UnmarshalText([]byte(cast.ToString(pagser.OutputValue(%v)))); err != nil {\n%v}\n", target, value, fail("err"))
+}
+
+// indexPath returns the go expression of slice item path
+func (g *generator) indexPath(path string, index string) string {
+	g.imports["strconv"] = true
+	return fmt.Sprintf("%v + \"[\" + strconv.Itoa(%v) + \"]\"", path, index)
+}
+
+// genNode generate the code to parse node to target of node type
+func (g *generator) genNode(ti *typeInfo, target string, node string, path string, fieldErr string) error {
+	fail := func(err string) string {
+		return fmt.Sprintf("return pagser.NewFieldError(%v, %v)\n", fieldErr, err)
+	}
+	switch ti.kind {
+	case kindUnmarshaler:
+		g.printf("if err := %v.UnmarshalSelection(%v); err != nil {\n%v}\n", target, node, fail("err"))
+	case kindStruct:
+		g.queue = append(g.queue, ti.expr)
+		g.printf("if err := %v.ParsePagser(%v); err != nil {\n%v}\n", target, node, fail("err"))
+	case kindAnonStruct:
+		obj := g.newVar("v")
+		g.printf("%v := &%v\n", obj, target)
+		if err := g.genFields(ti.fields, obj, node, path); err != nil {
+			return err
+		}
+	case kindPtr:
+		g.printf("%v = new(%v)\n", target, ti.elem.expr)
+		return g.genNode(ti.elem, "(*"+target+")", node, path, fieldErr)
+	case kindSlice:
+		index := g.newVar("i")
+		g.printf("%v = make(%v, %v.Size())\nfor %v := range %v {\n", target, ti.expr, node, index, target)
+		itemPath := g.indexPath(path, index)
+		itemErr := strings.Replace(fieldErr, path, itemPath, 1)
+		if err := g.genNode(ti.elem, fmt.Sprintf("%v[%v]", target, index), node+".Eq("+index+")", itemPath, itemErr); err != nil {
+			return err
+		}
+		g.printf("}\n")
+	default:
+		return fmt.Errorf("type %v is not supported", ti.expr)
+	}
+	return nil
+}
+
+// resolveType resolve the field type by the type specs of package
+func (g *generator) resolveType(expr ast.Expr) (*typeInfo, error) {
+	ti := &typeInfo{expr: g.source(expr)}
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if _, ok := castFuncs[t.Name]; ok {
+			ti.kind, ti.basic = kindBasic, t.Name
+			return ti, nil
+		}
+		if t.Name == "any" {
+			ti.kind = kindAny
+			return ti, nil
+		}
+		spec, ok := g.types[t.Name]
+		if !ok {
+			return nil, fmt.Errorf("type %v is not supported", t.Name)
+		}
+		switch methods := g.methods[t.Name]; {
+		case methods["UnmarshalSelection"] != nil:
+			ti.kind = kindUnmarshaler
+		case methods["UnmarshalText"] != nil && !g.hasTaggedFields(spec):
+			ti.kind = kindText
+		default:
+			switch underlying := spec.Type.(type) {
+			case *ast.StructType:
+				ti.kind = kindStruct
+			case *ast.Ident:
+				if _, ok := castFuncs[underlying.Name]; !ok {
+					return nil, fmt.Errorf("type %v is not supported", t.Name)
+				}
+				ti.kind, ti.basic, ti.named = kindBasic, underlying.Name, true
+			default:
+				return nil, fmt.Errorf("type %v is not supported", t.Name)
+			}
+		}
+	case *ast.InterfaceType:
+		if t.Methods.NumFields() > 0 {
+			return nil, fmt.Errorf("type %v is not supported", ti.expr)
+		}
+		ti.kind = kindAny
+	case *ast.SelectorExpr:
+		if ti.expr != "time.Time" {
+			return nil, fmt.Errorf("type %v is not supported", ti.expr)
+		}
+		ti.kind = kindTime
+	case *ast.StructType:
+		ti.kind, ti.fields = kindAnonStruct, t
+	case *ast.StarExpr:
+		elem, err := g.resolveType(t.X)
+		if err != nil {
+			return nil, err
+		}
+		if (!elem.isNode() || elem.kind == kindSlice) && elem.kind != kindTime && elem.kind != kindText {
+			return nil, fmt.Errorf("pointer type %v is not supported", ti.expr)
+		}
+		ti.kind, ti.elem = kindPtr, elem
+	case *ast.ArrayType:
+		if t.Len != nil {
+			return nil, fmt.Errorf("array type %v is not supported", ti.expr)
+		}
+		elem, err := g.resolveType(t.Elt)
+		if err != nil {
+			return nil, err
+		}
+		if elem.kind == kindSlice {
+			return nil, fmt.Errorf("type %v is not supported", ti.expr)
+		}
+		ti.kind, ti.elem = kindSlice, elem
+	case *ast.MapType:
+		return nil, fmt.Errorf("map type %v is not supported", ti.expr)
+	default:
+		return nil, fmt.Errorf("type %v is not supported", ti.expr)
+	}
+	return ti, nil
+}
+
+// convert returns the conversion expression of named basic type
+func (t *typeInfo) convert(value string) string {
+	if t.named {
+		return fmt.Sprintf("%v(%v)", t.expr, value)
+	}
+	return value
+}
+
+// fieldTag lookup the struct tag of field
+func (g *generator) fieldTag(field *ast.Field, name string) (string, bool) {
+	if field.Tag == nil {
+		return "", false
+	}
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return "", false
+	}
+	return reflect.StructTag(tag).Lookup(name)
+}
+
+// source returns the go source of node
+func (g *generator) source(node ast.Node) string {
+	start, end := g.fset.Position(node.Pos()), g.fset.Position(node.End())
+	return string(g.sources[start.Filename][start.Offset:end.Offset])
+}
+
+func (g *generator) matcherIndex(selector string) int {
+	for i, s := range g.matchers {
+		if s == selector {
+			return i
+		}
+	}
+	g.matchers = append(g.matchers, selector)
+	return len(g.matchers) - 1
+}
+
+func (g *generator) newVar(prefix string) string {
+	g.varSeq++
+	return prefix + strconv.Itoa(g.varSeq)
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(g.buf, format, args...)
+}
+
+// methodArgs convert the tag function params to the go arguments of struct method
+//	func (d PageData) MyFunc(node *goquery.Selection, name string, index int, args ...string) (out interface{}, err error)
+func methodArgs(method *ast.FuncType, fn *pagser.TagFunc) (string, error) {
+	paramTypes := make([]ast.Expr, 0)
+	for _, field := range method.Params.List {
+		count := len(field.Names)
+		if count == 0 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			paramTypes = append(paramTypes, field.Type)
+		}
+	}
+	if len(paramTypes) < 1 || types(paramTypes[0]) != "*goquery.Selection" {
+		return "", fmt.Errorf("method %v first argument must be *goquery.Selection", fn.Name)
+	}
+	if n := method.Results.NumFields(); n < 1 || n > 2 {
+		return "", fmt.Errorf("method %v must return (out interface{}, err error)", fn.Name)
+	}
+	paramTypes = paramTypes[1:]
+	variadic := len(paramTypes) > 0
+	if variadic {
+		_, variadic = paramTypes[len(paramTypes)-1].(*ast.Ellipsis)
+	}
+	fixedNum := len(paramTypes)
+	if variadic {
+		fixedNum--
+	}
+	if len(fn.Params) < fixedNum || (!variadic && len(fn.Params) > fixedNum) {
+		return "", fmt.Errorf("method %v expects %v arguments, but got %v", fn.Name, fixedNum, len(fn.Params))
+	}
+	args := strings.Builder{}
+	for i, param := range fn.Params {
+		var paramType ast.Expr
+		if i < fixedNum {
+			paramType = paramTypes[i]
+		} else {
+			paramType = paramTypes[len(paramTypes)-1].(*ast.Ellipsis).Elt
+		}
+		arg, err := literal(param, types(paramType))
+		if err != nil {
+			return "", fmt.Errorf("method %v argument %v: %v", fn.Name, i+1, err)
+		}
+		args.WriteString(", " + arg)
+	}
+	return args.String(), nil
+}
+
+// literal convert the tag function param to go literal of type
+func literal(param string, typeName string) (string, error) {
+	value := strings.TrimSpace(param)
+	var err error
+	switch typeName {
+	case "string":
+		return strconv.Quote(param), nil
+	case "bool":
+		var v bool
+		if v, err = strconv.ParseBool(value); err == nil {
+			return strconv.FormatBool(v), nil
+		}
+	case "int", "int8", "int16", "int32", "int64":
+		bits := map[string]int{"int": 0, "int8": 8, "int16": 16, "int32": 32, "int64": 64}[typeName]
+		var v int64
+		if v, err = strconv.ParseInt(value, 10, bits); err == nil {
+			return strconv.FormatInt(v, 10), nil
+		}
+	case "uint", "uint8", "uint16", "uint32", "uint64":
+		bits := map[string]int{"uint": 0, "uint8": 8, "uint16": 16, "uint32": 32, "uint64": 64}[typeName]
+		var v uint64
+		if v, err = strconv.ParseUint(value, 10, bits); err == nil {
+			return strconv.FormatUint(v, 10), nil
+		}
+	case "float32", "float64":
+		var v float64
+		if v, err = strconv.ParseFloat(value, 64); err == nil {
+			return typeName + "(" + strconv.FormatFloat(v, 'g', -1, 64) + ")", nil
+		}
+	default:
+		return "", fmt.Errorf("not support argument type %v", typeName)
+	}
+	return "", fmt.Errorf("`%v` is not %v", param, typeName)
+}
+
+// hasFuncs the tag or fallbacks has functions
+func hasFuncs(tag *pagser.TagInfo) bool {
+	if len(tag.Funcs) > 0 {
+		return true
+	}
+	for _, fallback := range tag.Fallbacks {
+		if hasFuncs(fallback) {
+			return true
+		}
+	}
+	return false
+}
+
+// quoteArgs returns the go string arguments of params
+func quoteArgs(params []string) string {
+	args := strings.Builder{}
+	for _, param := range params {
+		args.WriteString(", " + strconv.Quote(param))
+	}
+	return args.String()
+}
+
+// funcsString returns the functions pipeline, eg: `attr(href)->trim()`
+func funcsString(tag *pagser.TagInfo) string {
+	list := make([]string, len(tag.Funcs))
+	for i, fn := range tag.Funcs {
+		list[i] = fmt.Sprintf("%v(%v)", fn.Name, strings.Join(fn.Params, ", "))
+	}
+	return strings.Join(list, "->")
+}
+
+// joinPath returns the go expression of field path
+func joinPath(path string, name string) string {
+	if path == "" {
+		return strconv.Quote(name)
+	}
+	return path + " + " + strconv.Quote("."+name)
+}
+
+// embeddedName returns the field name of embedded field type
+func embeddedName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	}
+	return types(expr)
+}
+
+// types returns the type name of expression
+func types(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return "*" + types(t.X)
+	case *ast.SelectorExpr:
+		return types(t.X) + "." + t.Sel.Name
+	case *ast.Ellipsis:
+		return "..." + types(t.Elt)
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateExample(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "example_pagser.go")
+	t.Setenv("GOFILE", "example.go")
+	if err := run("internal/example", "", output, "pagser", "->"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile("internal/example/example_pagser.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("internal/example/example_pagser.go is out of date, run go generate:\n%s", got)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		err  string
+	}{
+		{"function", "type Data struct {\n\tA string `pagser:\"a->notExists()\"`\n}", "function `notExists`"},
+		{"selector", "type Data struct {\n\tA string `pagser:\"a[\"`\n}", "invalid selector"},
+		{"xpath", "type Data struct {\n\tA string `pagser:\"xpath://a\"`\n}", "xpath selector"},
+		{"map", "type Data struct {\n\tA map[string]string `pagser:\"a\"`\n}", "map type"},
+		{"options", "type Data struct {\n\tA string `pagser:\"a\" pagser_opts:\"required\"`\n}", "tag options"},
+		{"args", "type Data struct {\n\tA string `pagser:\"a->MyFunc(x)\"`\n}\n" +
+			"func (d Data) MyFunc(node *goquery.Selection, n int) (interface{}, error) {\n\treturn nil, nil\n}", "`x` is not int"},
+		{"parent method", "type Data struct {\n\tItem Item `pagser:\".item\"`\n}\n" +
+			"type Item struct {\n\tA string `pagser:\"a->MyFunc()\"`\n}\n" +
+			"func (d Data) MyFunc(node *goquery.Selection) (interface{}, error) {\n\treturn nil, nil\n}", "methods of parent struct"},
+		{"syntax", "type Data struct {\n\tA string `pagser:\"a->attr(\"`\n}", "syntax error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			src := "package data\n\nimport \"github.com/PuerkitoBio/goquery\"\n\nvar _ *goquery.Selection\n\n" + tt.src + "\n"
+			if err := os.WriteFile(filepath.Join(dir, "data.go"), []byte(src), 0644); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv("GOFILE", "")
+			err := run(dir, "", "", "pagser", "->")
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("error want contains `%v`, but got %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// Package example is the example of code generated by pagser-gen.
+package example
+
+import (
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/spf13/cast"
+)
+
+//go:generate go run github.com/foolin/pagser/cmd/pagser-gen
+
+// Status the stock status
+type Status string
+
+// Sku the product sku parsed from text
+type Sku struct {
+	Prefix string
+	Number string
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (s *Sku) UnmarshalText(text []byte) error {
+	s.Prefix, s.Number, _ = strings.Cut(string(text), "-")
+	return nil
+}
+
+// Money the price parsed from selection
+type Money struct {
+	Amount   string
+	Currency string
+}
+
+// UnmarshalSelection implements pagser.Unmarshaler
+func (m *Money) UnmarshalSelection(node *goquery.Selection) error {
+	m.Amount = node.Find(".amount").Text()
+	m.Currency = node.Find(".currency").Text()
+	return nil
+}
+
+// NavItem the navigation link
+type NavItem struct {
+	ID   int    `pagser:"->attrEmpty(id, -1)"`
+	Name string `pagser:"a->text()"`
+	Url  string `pagser:"a->attr(href)"`
+}
+
+// PageData the page data
+type PageData struct {
+	Title    string    `pagser:"title"`
+	Keywords []string  `pagser:"meta[name='keywords']->attrSplit(content)"`
+	Navs     []NavItem `pagser:".navlink li"`
+	Nav      *NavItem  `pagser:".navlink li->first()"`
+	Product  struct {
+		Name   string    `pagser:"h1->toUpper()"`
+		Status Status    `pagser:".status"`
+		Sku    Sku       `pagser:".sku"`
+		Price  Money     `pagser:".price"`
+		Rating float64   `pagser:".rating->Rating('/', 5)"`
+		Tags   []int     `pagser:".tags li"`
+		Date   time.Time `pagser:"time->attr(datetime)"`
+	} `pagser:".product"`
+	Author string `pagser:".author || .byline->trimPrefix('By ')"`
+	Ignore string `pagser:"-"`
+}
+
+// Rating parse the rating text like `4/5` to the ratio of scale
+func (d PageData) Rating(node *goquery.Selection, sep string, scale int) (out interface{}, err error) {
+	value, _, _ := strings.Cut(node.Text(), sep)
+	return cast.ToFloat64(strings.TrimSpace(value)) / float64(scale), nil
+}
+
+// LinkData the links resolved against the base url
+type LinkData struct {
+	Index string `pagser:".navlink a->absHref()"`
+	List  string `pagser:".navlink li[id] a->absHref()"`
+}
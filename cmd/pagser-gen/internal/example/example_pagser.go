@@ -0,0 +1,327 @@
+// Code generated by pagser-gen. DO NOT EDIT.
+
+package example
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+	"github.com/foolin/pagser"
+	"github.com/spf13/cast"
+)
+
+// ParsePagser parse the selection to LinkData, it is generated from the struct tags.
+func (v *LinkData) ParsePagser(sel *goquery.Selection) error {
+	var out interface{}
+	var err error
+
+	// Index `.navlink a->absHref()`
+	out = sel.FindMatcher(pagserLinkDataMatchers[0])
+	if err == nil {
+		var node *goquery.Selection
+		if node, err = pagser.OutputNode(out, "absHref"); err == nil {
+			out, err = pagser.BuiltinFunctions{}.AbsHref(node)
+		}
+	}
+	if errors.Is(err, pagser.ErrCast) {
+		out, err = nil, nil
+	}
+	if err != nil {
+		return pagser.NewFieldError("Index", ".navlink a->absHref()", ".navlink a", "absHref()", err)
+	}
+	v.Index = cast.ToString(pagser.OutputValue(out))
+
+	// List `.navlink li[id] a->absHref()`
+	out = sel.FindMatcher(pagserLinkDataMatchers[1])
+	if err == nil {
+		var node *goquery.Selection
+		if node, err = pagser.OutputNode(out, "absHref"); err == nil {
+			out, err = pagser.BuiltinFunctions{}.AbsHref(node)
+		}
+	}
+	if errors.Is(err, pagser.ErrCast) {
+		out, err = nil, nil
+	}
+	if err != nil {
+		return pagser.NewFieldError("List", ".navlink li[id] a->absHref()", ".navlink li[id] a", "absHref()", err)
+	}
+	v.List = cast.ToString(pagser.OutputValue(out))
+	return nil
+}
+
+// PagserTags returns the tag name and function symbol of the struct tags ParsePagser is generated from.
+func (v *LinkData) PagserTags() (string, string) {
+	return "pagser", "->"
+}
+
+var pagserLinkDataMatchers = []goquery.Matcher{
+	cascadia.MustCompile(".navlink a"),
+	cascadia.MustCompile(".navlink li[id] a"),
+}
+
+// ParsePagser parse the selection to NavItem, it is generated from the struct tags.
+func (v *NavItem) ParsePagser(sel *goquery.Selection) error {
+	var out interface{}
+	var err error
+
+	// ID `->attrEmpty(id, -1)`
+	out = sel
+	if err == nil {
+		var node *goquery.Selection
+		if node, err = pagser.OutputNode(out, "attrEmpty"); err == nil {
+			out, err = pagser.BuiltinFunctions{}.AttrEmpty(node, "id", "-1")
+		}
+	}
+	if errors.Is(err, pagser.ErrCast) {
+		out, err = nil, nil
+	}
+	if err != nil {
+		return pagser.NewFieldError("ID", "->attrEmpty(id, -1)", "", "attrEmpty(id, -1)", err)
+	}
+	v.ID = cast.ToInt(pagser.OutputValue(out))
+
+	// Name `a->text()`
+	out = sel.FindMatcher(pagserNavItemMatchers[0])
+	if err == nil {
+		var node *goquery.Selection
+		if node, err = pagser.OutputNode(out, "text"); err == nil {
+			out, err = pagser.BuiltinFunctions{}.Text(node)
+		}
+	}
+	if errors.Is(err, pagser.ErrCast) {
+		out, err = nil, nil
+	}
+	if err != nil {
+		return pagser.NewFieldError("Name", "a->text()", "a", "text()", err)
+	}
+	v.Name = cast.ToString(pagser.OutputValue(out))
+
+	// Url `a->attr(href)`
+	out = sel.FindMatcher(pagserNavItemMatchers[0])
+	if err == nil {
+		var node *goquery.Selection
+		if node, err = pagser.OutputNode(out, "attr"); err == nil {
+			out, err = pagser.BuiltinFunctions{}.Attr(node, "href")
+		}
+	}
+	if errors.Is(err, pagser.ErrCast) {
+		out, err = nil, nil
+	}
+	if err != nil {
+		return pagser.NewFieldError("Url", "a->attr(href)", "a", "attr(href)", err)
+	}
+	v.Url = cast.ToString(pagser.OutputValue(out))
+	return nil
+}
+
+// PagserTags returns the tag name and function symbol of the struct tags ParsePagser is generated from.
+func (v *NavItem) PagserTags() (string, string) {
+	return "pagser", "->"
+}
+
+var pagserNavItemMatchers = []goquery.Matcher{
+	cascadia.MustCompile("a"),
+}
+
+// ParsePagser parse the selection to PageData, it is generated from the struct tags.
+func (v *PageData) ParsePagser(sel *goquery.Selection) error {
+	var out interface{}
+	var err error
+
+	// Title `title`
+	out = sel.FindMatcher(pagserPageDataMatchers[0])
+	v.Title = cast.ToString(pagser.OutputValue(out))
+
+	// Keywords `meta[name='keywords']->attrSplit(content)`
+	out = sel.FindMatcher(pagserPageDataMatchers[1])
+	if err == nil {
+		var node *goquery.Selection
+		if node, err = pagser.OutputNode(out, "attrSplit"); err == nil {
+			out, err = pagser.BuiltinFunctions{}.AttrSplit(node, "content")
+		}
+	}
+	if errors.Is(err, pagser.ErrCast) {
+		out, err = nil, nil
+	}
+	if err != nil {
+		return pagser.NewFieldError("Keywords", "meta[name='keywords']->attrSplit(content)", "meta[name='keywords']", "attrSplit(content)", err)
+	}
+	{
+		items1 := pagser.OutputList(out)
+		v.Keywords = make([]string, len(items1))
+		for i2 := range items1 {
+			v.Keywords[i2] = cast.ToString(pagser.OutputValue(items1[i2]))
+		}
+	}
+
+	// Navs `.navlink li`
+	out = sel.FindMatcher(pagserPageDataMatchers[2])
+	{
+		node3, err := pagser.OutputNode(out, "")
+		if err != nil {
+			return pagser.NewFieldError("Navs", ".navlink li", ".navlink li", "", err)
+		}
+		v.Navs = make([]NavItem, node3.Size())
+		for i4 := range v.Navs {
+			if err := v.Navs[i4].ParsePagser(node3.Eq(i4)); err != nil {
+				return pagser.NewFieldError("Navs"+"["+strconv.Itoa(i4)+"]", ".navlink li", ".navlink li", "", err)
+			}
+		}
+	}
+
+	// Nav `.navlink li->first()`
+	out = sel.FindMatcher(pagserPageDataMatchers[2])
+	if err == nil {
+		var node *goquery.Selection
+		if node, err = pagser.OutputNode(out, "first"); err == nil {
+			out, err = pagser.BuiltinSelections{}.First(node)
+		}
+	}
+	if errors.Is(err, pagser.ErrCast) {
+		out, err = nil, nil
+	}
+	if err != nil {
+		return pagser.NewFieldError("Nav", ".navlink li->first()", ".navlink li", "first()", err)
+	}
+	{
+		node5, err := pagser.OutputNode(out, "")
+		if err != nil {
+			return pagser.NewFieldError("Nav", ".navlink li->first()", ".navlink li", "first()", err)
+		}
+		v.Nav = new(NavItem)
+		if err := (*v.Nav).ParsePagser(node5); err != nil {
+			return pagser.NewFieldError("Nav", ".navlink li->first()", ".navlink li", "first()", err)
+		}
+	}
+
+	// Product `.product`
+	out = sel.FindMatcher(pagserPageDataMatchers[3])
+	{
+		node6, err := pagser.OutputNode(out, "")
+		if err != nil {
+			return pagser.NewFieldError("Product", ".product", ".product", "", err)
+		}
+		v7 := &v.Product
+
+		// Name `h1->toUpper()`
+		out = node6.FindMatcher(pagserPageDataMatchers[4])
+		if err == nil {
+			out, err = pagser.BuiltinValues{}.ToUpper(pagser.OutputValue(out))
+		}
+		if errors.Is(err, pagser.ErrCast) {
+			out, err = nil, nil
+		}
+		if err != nil {
+			return pagser.NewFieldError("Product"+".Name", "h1->toUpper()", "h1", "toUpper()", err)
+		}
+		v7.Name = cast.ToString(pagser.OutputValue(out))
+
+		// Status `.status`
+		out = node6.FindMatcher(pagserPageDataMatchers[5])
+		v7.Status = Status(cast.ToString(pagser.OutputValue(out)))
+
+		// Sku `.sku`
+		out = node6.FindMatcher(pagserPageDataMatchers[6])
+		if out != nil {
+			if err := v7.Sku.UnmarshalText([]byte(cast.ToString(pagser.OutputValue(out)))); err != nil {
+				return pagser.NewFieldError("Product"+".Sku", ".sku", ".sku", "", err)
+			}
+		}
+
+		// Price `.price`
+		out = node6.FindMatcher(pagserPageDataMatchers[7])
+		{
+			node8, err := pagser.OutputNode(out, "")
+			if err != nil {
+				return pagser.NewFieldError("Product"+".Price", ".price", ".price", "", err)
+			}
+			if err := v7.Price.UnmarshalSelection(node8); err != nil {
+				return pagser.NewFieldError("Product"+".Price", ".price", ".price", "", err)
+			}
+		}
+
+		// Rating `.rating->Rating('/', 5)`
+		out = node6.FindMatcher(pagserPageDataMatchers[8])
+		if err == nil {
+			var node *goquery.Selection
+			if node, err = pagser.OutputNode(out, "Rating"); err == nil {
+				out, err = v.Rating(node, "/", 5)
+			}
+		}
+		if errors.Is(err, pagser.ErrCast) {
+			out, err = nil, nil
+		}
+		if err != nil {
+			return pagser.NewFieldError("Product"+".Rating", ".rating->Rating('/', 5)", ".rating", "Rating(/, 5)", err)
+		}
+		v7.Rating = cast.ToFloat64(pagser.OutputValue(out))
+
+		// Tags `.tags li`
+		out = node6.FindMatcher(pagserPageDataMatchers[9])
+		{
+			items9 := pagser.OutputList(out)
+			v7.Tags = make([]int, len(items9))
+			for i10 := range items9 {
+				v7.Tags[i10] = cast.ToInt(pagser.OutputValue(items9[i10]))
+			}
+		}
+
+		// Date `time->attr(datetime)`
+		out = node6.FindMatcher(pagserPageDataMatchers[10])
+		if err == nil {
+			var node *goquery.Selection
+			if node, err = pagser.OutputNode(out, "attr"); err == nil {
+				out, err = pagser.BuiltinFunctions{}.Attr(node, "datetime")
+			}
+		}
+		if errors.Is(err, pagser.ErrCast) {
+			out, err = nil, nil
+		}
+		if err != nil {
+			return pagser.NewFieldError("Product"+".Date", "time->attr(datetime)", "time", "attr(datetime)", err)
+		}
+		if value, err := pagser.ToTimeE(pagser.OutputValue(out)); err == nil {
+			v7.Date = value
+		}
+	}
+
+	// Author `.author || .byline->trimPrefix('By ')`
+	out = sel.FindMatcher(pagserPageDataMatchers[11])
+	if err == nil && pagser.NeedFallback(out) {
+		out = sel.FindMatcher(pagserPageDataMatchers[12])
+		if err == nil {
+			out, err = pagser.BuiltinValues{}.TrimPrefix(pagser.OutputValue(out), "By ")
+		}
+		if errors.Is(err, pagser.ErrCast) {
+			out, err = nil, nil
+		}
+	}
+	if err != nil {
+		return pagser.NewFieldError("Author", ".author || .byline->trimPrefix('By ')", ".author", "", err)
+	}
+	v.Author = cast.ToString(pagser.OutputValue(out))
+	return nil
+}
+
+// PagserTags returns the tag name and function symbol of the struct tags ParsePagser is generated from.
+func (v *PageData) PagserTags() (string, string) {
+	return "pagser", "->"
+}
+
+var pagserPageDataMatchers = []goquery.Matcher{
+	cascadia.MustCompile("title"),
+	cascadia.MustCompile("meta[name='keywords']"),
+	cascadia.MustCompile(".navlink li"),
+	cascadia.MustCompile(".product"),
+	cascadia.MustCompile("h1"),
+	cascadia.MustCompile(".status"),
+	cascadia.MustCompile(".sku"),
+	cascadia.MustCompile(".price"),
+	cascadia.MustCompile(".rating"),
+	cascadia.MustCompile(".tags li"),
+	cascadia.MustCompile("time"),
+	cascadia.MustCompile(".author"),
+	cascadia.MustCompile(".byline"),
+}
@@ -0,0 +1,116 @@
+package example
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/foolin/pagser"
+)
+
+const rawExampleHtml = `
+<html>
+<head>
+	<title>Example</title>
+	<meta name="keywords" content="golang, pagser">
+</head>
+<body>
+	<div class="navlink">
+		<ul>
+			<li><a href="/">Index</a></li>
+			<li id="2"><a href="/list">List</a></li>
+		</ul>
+	</div>
+	<div class="product">
+		<h1>Gopher</h1>
+		<span class="status">in_stock</span>
+		<span class="sku">GO-123</span>
+		<span class="price"><i class="currency">USD</i><b class="amount">9.9</b></span>
+		<span class="rating">4/5</span>
+		<ul class="tags"><li>1</li><li>2</li></ul>
+		<time datetime="2020-01-02T03:04:05Z">Jan 2</time>
+	</div>
+	<span class="byline">By Foolin</span>
+</body>
+</html>
+`
+
+func TestParsePagser(t *testing.T) {
+	var data PageData
+	if err := pagser.New().Parse(&data, rawExampleHtml); err != nil {
+		t.Fatal(err)
+	}
+	want := PageData{
+		Title:    "Example",
+		Keywords: []string{"golang", "pagser"},
+		Navs:     []NavItem{{ID: -1, Name: "Index", Url: "/"}, {ID: 2, Name: "List", Url: "/list"}},
+		Nav:      &NavItem{ID: -1, Name: "Index", Url: "/"},
+		Author:   "Foolin",
+	}
+	want.Product.Name = "GOPHER"
+	want.Product.Status = "in_stock"
+	want.Product.Sku = Sku{Prefix: "GO", Number: "123"}
+	want.Product.Price = Money{Amount: "9.9", Currency: "USD"}
+	want.Product.Rating = 0.8
+	want.Product.Tags = []int{1, 2}
+	want.Product.Date = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("want %#v, but got %#v", want, data)
+	}
+}
+
+const rawLinkHtml = `
+<html>
+<head><base href="https://example.org/"></head>
+<body>
+	<ul class="navlink">
+		<li><a href="/">Index</a></li>
+		<li id="2"><a href="list">List</a></li>
+	</ul>
+</body>
+</html>
+`
+
+// reflectLinkData the same tags as LinkData, it is parsed by reflection
+type reflectLinkData struct {
+	Index string `pagser:".navlink a->absHref()"`
+	List  string `pagser:".navlink li[id] a->absHref()"`
+}
+
+func TestParsePagserBaseURL(t *testing.T) {
+	p := pagser.New()
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(rawLinkHtml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var generated LinkData
+	if err := generated.ParsePagser(doc.Selection); err != nil {
+		t.Fatal(err)
+	}
+	var data LinkData
+	if err := p.Parse(&data, rawLinkHtml); err != nil {
+		t.Fatal(err)
+	}
+	if data != (LinkData{Index: "https://example.org/", List: "https://example.org/list"}) || data != generated {
+		t.Errorf("want generated %#v, but got %#v", generated, data)
+	}
+
+	//the relative `<base href>` is resolved against the url of ParseWithBaseURL
+	relativeHtml := strings.Replace(rawLinkHtml, "https://example.org/", "/docs/", 1)
+	var want reflectLinkData
+	if err := p.ParseWithBaseURL(&want, relativeHtml, "https://example.com/"); err != nil {
+		t.Fatal(err)
+	}
+	if want.Index != "https://example.com/" || want.List != "https://example.com/docs/list" {
+		t.Fatalf("links must be resolved against base url, but got %#v", want)
+	}
+	data = LinkData{}
+	if err := p.ParseWithBaseURL(&data, relativeHtml, "https://example.com/"); err != nil {
+		t.Fatal(err)
+	}
+	if data != LinkData(want) {
+		t.Errorf("want reflection %#v, but got %#v", want, data)
+	}
+}
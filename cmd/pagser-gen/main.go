@@ -0,0 +1,118 @@
+// Command pagser-gen generates the ParsePagser methods of structs with pagser tags,
+// Pagser.ParseSelection calls the generated method instead of reflection.
+//
+// Usage:
+//	//go:generate go run github.com/foolin/pagser/cmd/pagser-gen -type PageData,ItemData
+//
+// Flags:
+//	-type    comma-separated list of struct types, default is all the tagged structs of $GOFILE or package
+//	-output  output file name, default is <file>_pagser.go of $GOFILE, or pagser_gen.go
+//	-tag     struct tag name, default is pagser
+//	-symbol  function symbol, default is ->
+//
+// The struct methods, builtin functions and builtin value functions are called directly,
+// and the selectors, function names and arguments are checked at generation time.
+// The methods of parent struct can not be called by the nested struct types.
+// Registered functions, methods with context argument, map fields, tag options and xpath selectors are not supported.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/build"
+	"go/parser"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/foolin/pagser"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma-separated list of struct types")
+	output := flag.String("output", "", "output file name")
+	tagName := flag.String("tag", "pagser", "struct tag name")
+	funcSymbol := flag.String("symbol", "->", "function symbol")
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	if err := run(dir, *typeNames, *output, *tagName, *funcSymbol); err != nil {
+		fmt.Fprintf(os.Stderr, "pagser-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir string, typeNames string, output string, tagName string, funcSymbol string) error {
+	goFile := os.Getenv("GOFILE")
+	if output == "" {
+		output = "pagser_gen.go"
+		if goFile != "" {
+			output = strings.TrimSuffix(goFile, ".go") + "_pagser.go"
+		}
+	}
+	if !filepath.IsAbs(output) {
+		output = filepath.Join(dir, output)
+	}
+
+	cfg := pagser.DefaultConfig()
+	cfg.TagName = tagName
+	cfg.FuncSymbol = funcSymbol
+	p, err := pagser.NewWithConfig(cfg)
+	if err != nil {
+		return err
+	}
+	g := newGenerator(p)
+
+	pkg, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return err
+	}
+	for _, name := range pkg.GoFiles {
+		fileName := filepath.Join(dir, name)
+		if sameFile(fileName, output) {
+			continue
+		}
+		src, err := os.ReadFile(fileName)
+		if err != nil {
+			return err
+		}
+		file, err := parser.ParseFile(g.fset, fileName, src, parser.ParseComments)
+		if err != nil {
+			return err
+		}
+		g.sources[fileName] = src
+		g.addFile(file)
+	}
+
+	var names []string
+	if typeNames != "" {
+		names = strings.Split(typeNames, ",")
+		for i, name := range names {
+			names[i] = strings.TrimSpace(name)
+		}
+	} else {
+		fileName := ""
+		if goFile != "" {
+			fileName = filepath.Join(dir, goFile)
+		}
+		names = g.taggedTypes(fileName)
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("no struct with `%v` tags in %v", tagName, dir)
+	}
+
+	src, err := g.generate(pkg.Name, names)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(output, src, 0644)
+}
+
+func sameFile(a string, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
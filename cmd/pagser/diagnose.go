@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/foolin/pagser"
+)
+
+// diagnose compare the rules on an old and a new html file, and write the broken fields with suggested tags
+func diagnose(args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := flag.NewFlagSet("pagser diagnose", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var ruleValues ruleFlags
+	flags.Var(&ruleValues, "f", "rule `name=selector`, repeatable")
+	rulesFile := flags.String("rules", "", "rules file of JSON or YAML")
+	format := flags.String("format", "text", "output format: text or json")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return errors.New("old and new html files are required")
+	}
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("unknown format `%v`, it must be text or json", *format)
+	}
+	rules, err := loadRules(*rulesFile, ruleValues)
+	if err != nil {
+		return err
+	}
+	oldDoc, err := openDocument(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	newDoc, err := openDocument(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+	diagnosis, err := newPagser().DiagnoseRules(rules, oldDoc.Selection, newDoc.Selection)
+	if err != nil {
+		return err
+	}
+	if *format == "json" {
+		err = writeDiagnosisJson(stdout, diagnosis)
+	} else {
+		err = writeDiagnosisText(stdout, diagnosis)
+	}
+	if err != nil {
+		return err
+	}
+	if broken := diagnosis.Broken(); len(broken) > 0 {
+		return fmt.Errorf("%v of %v fields are broken", len(broken), len(diagnosis.Fields))
+	}
+	return nil
+}
+
+// openDocument open the html file as document
+func openDocument(name string) (*goquery.Document, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return pagser.NewDocument(f, "")
+}
+
+// writeDiagnosisText write a line of each field, and the values and suggestions of broken fields
+func writeDiagnosisText(w io.Writer, diagnosis *pagser.Diagnosis) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "FIELD\tSTATUS\tSCORE\tTAG")
+	for _, field := range diagnosis.Fields {
+		fmt.Fprintf(tw, "%v\t%v\t%.2f\t%v\n", field.Path, field.Status, field.Score, field.Tag)
+		if field.Status == pagser.DiagnoseOK {
+			continue
+		}
+		if field.Err != nil {
+			fmt.Fprintf(tw, "\t\t\terror: %v\n", field.Err)
+		}
+		if field.OldValue != nil {
+			fmt.Fprintf(tw, "\t\t\told: %v (%v nodes)\n", jsonString(field.OldValue), field.OldMatched)
+		}
+		if field.Status == pagser.DiagnoseChanged {
+			fmt.Fprintf(tw, "\t\t\tnew: %v (%v nodes)\n", jsonString(field.NewValue), field.NewMatched)
+		}
+		for _, suggestion := range field.Suggestions {
+			fmt.Fprintf(tw, "\t\t%.2f\tsuggest: %v\n", suggestion.Score, suggestion.Tag)
+		}
+	}
+	return tw.Flush()
+}
+
+// writeDiagnosisJson write the fields as json array
+func writeDiagnosisJson(w io.Writer, diagnosis *pagser.Diagnosis) error {
+	list := make([]map[string]interface{}, len(diagnosis.Fields))
+	for i, field := range diagnosis.Fields {
+		suggestions := make([]map[string]interface{}, len(field.Suggestions))
+		for j, suggestion := range field.Suggestions {
+			suggestions[j] = map[string]interface{}{"tag": suggestion.Tag, "score": suggestion.Score, "matched": suggestion.Matched}
+		}
+		record := map[string]interface{}{
+			"path":        field.Path,
+			"tag":         field.Tag,
+			"score":       field.Score,
+			"status":      field.Status,
+			"oldMatched":  field.OldMatched,
+			"newMatched":  field.NewMatched,
+			"oldValue":    pagser.OutputJson(field.OldValue),
+			"newValue":    pagser.OutputJson(field.NewValue),
+			"suggestions": suggestions,
+		}
+		if field.Err != nil {
+			record["error"] = field.Err.Error()
+		}
+		list[i] = record
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(list)
+}
+
+// jsonString returns the json of value
+func jsonString(value interface{}) string {
+	data, err := json.Marshal(pagser.OutputJson(value))
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/foolin/pagser"
+	"github.com/foolin/pagser/extensions/markdown"
+	"github.com/foolin/pagser/extensions/ugchtml"
+)
+
+// sourceKey the record key of input file if there are multiple inputs
+const sourceKey = "_source"
+
+// input the html input, name is `-` for stdin
+type input struct {
+	name string
+	open func() (io.ReadCloser, error)
+}
+
+// extract parse the inputs by rules and write the records
+func extract(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	flags := flag.NewFlagSet("pagser", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var ruleValues ruleFlags
+	flags.Var(&ruleValues, "f", "rule `name=selector`, repeatable")
+	rulesFile := flags.String("rules", "", "rules file of JSON or YAML")
+	format := flags.String("format", "json", "output format: json, ndjson or csv")
+	baseURL := flags.String("base", "", "base url of absHref(), absSrc() and absAttr(name)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	rules, err := loadRules(*rulesFile, ruleValues)
+	if err != nil {
+		return err
+	}
+	inputs, err := findInputs(flags.Args(), stdin)
+	if err != nil {
+		return err
+	}
+	w, err := newRecordWriter(*format, stdout, rules, len(inputs) > 1)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	if *baseURL != "" {
+		u, err := url.Parse(*baseURL)
+		if err != nil {
+			return fmt.Errorf("invalid base url: %v", err)
+		}
+		ctx = pagser.WithBaseURL(ctx, u)
+	}
+
+	p := newPagser()
+	failed := 0
+	for _, in := range inputs {
+		record, err := parseInput(ctx, p, in, rules)
+		if err != nil {
+			failed++
+			fmt.Fprintf(stderr, "pagser: %v: %v\n", in.name, err)
+			if record == nil {
+				continue
+			}
+		}
+		if len(inputs) > 1 {
+			record[sourceKey] = in.name
+		}
+		if err = w.Write(record); err != nil {
+			return err
+		}
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%v of %v inputs failed", failed, len(inputs))
+	}
+	return nil
+}
+
+// newPagser returns pagser with extension functions, and continue on field errors
+func newPagser() *pagser.Pagser {
+	cfg := pagser.DefaultConfig()
+	cfg.ContinueOnError = true
+	p, _ := pagser.NewWithConfig(cfg)
+	markdown.Register(p)
+	ugchtml.Register(p)
+	return p
+}
+
+// loadRules load the rules file and `-f` rules, the `-f` rules replace the rules of the same name
+func loadRules(rulesFile string, ruleValues []string) (pagser.Rules, error) {
+	rules := make(pagser.Rules)
+	if rulesFile != "" {
+		fileRules, err := pagser.LoadRulesFile(rulesFile)
+		if err != nil {
+			return nil, err
+		}
+		for name, rule := range fileRules {
+			rules[name] = rule
+		}
+	}
+	for _, value := range ruleValues {
+		name, selector, _ := strings.Cut(value, "=")
+		rules[strings.TrimSpace(name)] = &pagser.Rule{Selector: strings.TrimSpace(selector)}
+	}
+	if len(rules) == 0 {
+		return nil, errors.New("no rules, use -f name=selector or -rules file")
+	}
+	return rules, nil
+}
+
+// findInputs returns the inputs of files, directories and globs, or stdin if args is empty
+func findInputs(args []string, stdin io.Reader) ([]*input, error) {
+	if len(args) == 0 {
+		args = []string{"-"}
+	}
+	var inputs []*input
+	for _, arg := range args {
+		if arg == "-" {
+			inputs = append(inputs, &input{name: "-", open: func() (io.ReadCloser, error) {
+				return io.NopCloser(stdin), nil
+			}})
+			continue
+		}
+		files, err := expandPath(arg)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			file := file
+			inputs = append(inputs, &input{name: file, open: func() (io.ReadCloser, error) {
+				return os.Open(file)
+			}})
+		}
+	}
+	return inputs, nil
+}
+
+// expandPath returns the files of glob, or the html files of directory
+func expandPath(path string) ([]string, error) {
+	if strings.ContainsAny(path, "*?[") {
+		files, err := filepath.Glob(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob %v: %v", path, err)
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no files match %v", path)
+		}
+		return files, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	var files []string
+	err = filepath.WalkDir(path, func(file string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		ext := strings.ToLower(filepath.Ext(file))
+		if !d.IsDir() && (ext == ".html" || ext == ".htm") {
+			files = append(files, file)
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+// parseInput parse the input by rules, the record is returned with the field errors
+func parseInput(ctx context.Context, p *pagser.Pagser, in *input, rules pagser.Rules) (map[string]interface{}, error) {
+	reader, err := in.open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	doc, err := pagser.NewDocument(reader, "")
+	if err != nil {
+		return nil, err
+	}
+	return p.ParseSelectionWithRulesContext(ctx, doc.Selection, rules)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/foolin/pagser"
+	"github.com/foolin/pagser/infer"
+)
+
+// inferStruct generate the struct with pagser tags from a sample file and the example values
+func inferStruct(args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := flag.NewFlagSet("pagser infer", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	specFile := flags.String("spec", "", "spec file of `name=value` lines")
+	typeName := flags.String("type", "Page", "struct type name")
+	pkg := flags.String("package", "main", "package name")
+	output := flags.String("o", "", "output file, default is stdout")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return errors.New("sample html file is required")
+	}
+	sample := flags.Arg(0)
+
+	lines := flags.Args()[1:]
+	if *specFile != "" {
+		data, err := os.ReadFile(*specFile)
+		if err != nil {
+			return err
+		}
+		lines = append(strings.Split(string(data), "\n"), lines...)
+	}
+	fields, err := infer.ParseSpecLines(lines)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(sample)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	doc, err := pagser.NewDocument(f, "")
+	if err != nil {
+		return err
+	}
+	results, err := infer.Infer(doc.Selection, fields)
+	if err != nil {
+		return err
+	}
+	cfg := infer.DefaultConfig()
+	cfg.Package = *pkg
+	cfg.TypeName = *typeName
+	cfg.Comment = fmt.Sprintf("%v the fields of %v", *typeName, filepath.Base(sample))
+	source, err := infer.Generate(results, cfg)
+	if err != nil {
+		return err
+	}
+	if *output != "" {
+		return os.WriteFile(*output, source, 0644)
+	}
+	_, err = stdout.Write(source)
+	return err
+}
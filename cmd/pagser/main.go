@@ -0,0 +1,72 @@
+// Command pagser extracts values from html files or stdin by ad-hoc selector rules,
+// the rules have the same grammar as struct tags, and the builtin and extension functions are registered.
+//
+// Usage:
+//	pagser [flags] [file|dir|glob|-]...
+//	curl -s https://github.com/trending | pagser -f title='title' -f repos='article h2 a->eachAttr(href)'
+//	pagser -rules rules.yaml -format csv 'pages/*.html'
+//	pagser serve -addr localhost:8080
+//	pagser infer -type Product sample.html title='"Pagser"' price=19.99 tags=go tags=html
+//	pagser diagnose -rules rules.yaml old.html new.html
+//
+// Flags:
+//	-f       rule `name=selector`, repeatable
+//	-rules   rules file of JSON or YAML, see pagser.LoadRules
+//	-format  output format: json, ndjson or csv, default is json
+//	-base    base url of absHref(), absSrc() and absAttr(name)
+//
+// The input is stdin if no file is given, directories are searched for *.html and *.htm files.
+// The records of multiple inputs have a `_source` value of the input file.
+//
+// The serve command starts the playground of package playground, to try selectors and rules on pasted html.
+// The infer command writes a struct with pagser tags inferred from a sample file and `name=value` examples
+// of arguments or -spec file, see package infer.
+// The diagnose command compares the rules on an old page that was parsed correctly and a new page,
+// and writes the fields match nothing or different values with the suggested tags, see Pagser.Diagnose.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// ruleFlags the repeatable `-f name=selector` flag
+type ruleFlags []string
+
+func (f *ruleFlags) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *ruleFlags) Set(value string) error {
+	if name, _, ok := strings.Cut(value, "="); !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("rule `%v` must be name=selector", value)
+	}
+	*f = append(*f, value)
+	return nil
+}
+
+// run the command with arguments, and returns the exit code
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	var err error
+	switch {
+	case len(args) > 0 && args[0] == "serve":
+		err = serve(args[1:], stdout, stderr)
+	case len(args) > 0 && args[0] == "infer":
+		err = inferStruct(args[1:], stdout, stderr)
+	case len(args) > 0 && args[0] == "diagnose":
+		err = diagnose(args[1:], stdout, stderr)
+	default:
+		err = extract(args, stdin, stdout, stderr)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "pagser: %v\n", err)
+		return 1
+	}
+	return 0
+}
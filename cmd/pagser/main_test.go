@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const rawListHtml = `<html><head><title>%v</title></head>
+<body><ul><li><a href="/a">A</a></li><li><a href="b">B</a></li></ul><div class="content"><h2>Hello</h2></div></body></html>`
+
+func writeHtmlFiles(t *testing.T, dir string, titles ...string) {
+	for _, title := range titles {
+		html := strings.Replace(rawListHtml, "%v", title, 1)
+		if err := os.WriteFile(filepath.Join(dir, title+".html"), []byte(html), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func runCommand(stdin string, args ...string) (string, string, int) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return stdout.String(), stderr.String(), code
+}
+
+func TestRunStdin(t *testing.T) {
+	stdin := strings.Replace(rawListHtml, "%v", "Page", 1)
+	stdout, stderr, code := runCommand(stdin, "-base", "https://example.com/list/",
+		"-f", "title=title", "-f", "links=li a->eachAttr(href)", "-f", "first=li a->absHref()", "-f", "content=.content->Markdown()")
+	if code != 0 {
+		t.Fatalf("exit %v: %v", code, stderr)
+	}
+	want := `{
+  "content": "## Hello\n\n",
+  "first": "https://example.com/a",
+  "links": [
+    "/a",
+    "b"
+  ],
+  "title": "Page"
+}
+`
+	if stdout != want {
+		t.Fatalf("want %v, but got %v", want, stdout)
+	}
+}
+
+func TestRunFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeHtmlFiles(t, dir, "one", "two")
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("<title>notes</title>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rulesFile := filepath.Join(dir, "rules.yaml")
+	rules := "title: title\nitems:\n  selector: li a\n  list: true\n"
+	if err := os.WriteFile(rulesFile, []byte(rules), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	//directory and ndjson
+	stdout, stderr, code := runCommand("", "-rules", rulesFile, "-format", "ndjson", dir)
+	if code != 0 {
+		t.Fatalf("exit %v: %v", code, stderr)
+	}
+	want := `{"_source":"` + filepath.Join(dir, "one.html") + `","items":["A","B"],"title":"one"}
+{"_source":"` + filepath.Join(dir, "two.html") + `","items":["A","B"],"title":"two"}
+`
+	if stdout != want {
+		t.Fatalf("want %v, but got %v", want, stdout)
+	}
+
+	//glob and csv, -f replaces the rule of rules file
+	stdout, stderr, code = runCommand("", "-rules", rulesFile, "-f", "title=title->toUpper()", "-format", "csv", filepath.Join(dir, "t*.html"))
+	if code != 0 {
+		t.Fatalf("exit %v: %v", code, stderr)
+	}
+	if want = "items,title\n\"[\"\"A\"\",\"\"B\"\"]\",TWO\n"; stdout != want {
+		t.Fatalf("want %v, but got %v", want, stdout)
+	}
+
+	//json array of multiple files
+	stdout, _, code = runCommand("", "-f", "title=title", filepath.Join(dir, "one.html"), filepath.Join(dir, "two.html"))
+	if code != 0 || !strings.HasPrefix(stdout, "[") || !strings.Contains(stdout, `"title": "two"`) {
+		t.Fatalf("want json array, but got %v", stdout)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeHtmlFiles(t, dir, "one")
+	tests := [][]string{
+		{dir},
+		{"-f", "title=title", "-format", "xml", dir},
+		{"-f", "title=title", filepath.Join(dir, "missing.html")},
+		{"-f", "title=title", filepath.Join(dir, "*.htm")},
+		{"-f", "=title", dir},
+		{"-rules", filepath.Join(dir, "missing.yaml"), dir},
+	}
+	for _, args := range tests {
+		if _, _, code := runCommand("", args...); code == 0 {
+			t.Errorf("%v want exit code 1", args)
+		}
+	}
+
+	//field errors are reported, and the other values are written
+	stdout, stderr, code := runCommand("<p>x</p>", "-format", "csv", "-f", "p=p", "-f", "bad=p->nope()")
+	if code != 1 || stdout != "bad,p\n,x\n" || !strings.Contains(stderr, "not found method nope") {
+		t.Fatalf("unexpected output: %v %v %v", code, stdout, stderr)
+	}
+}
+
+func TestRunServe(t *testing.T) {
+	defer func(fn func(string, http.Handler) error) { listenAndServe = fn }(listenAndServe)
+	var handler http.Handler
+	listenAndServe = func(addr string, h http.Handler) error {
+		if addr != ":9090" {
+			t.Errorf("want addr :9090, but got %v", addr)
+		}
+		handler = h
+		return nil
+	}
+	if _, stderr, code := runCommand("", "serve", "-addr", ":9090"); code != 0 {
+		t.Fatalf("exit %v: %v", code, stderr)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/evaluate", strings.NewReader(`{"html":"<p>x</p>","expr":"p->toUpper()"}`)))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"result":"X"`) {
+		t.Fatalf("unexpected response %v: %v", rec.Code, rec.Body.String())
+	}
+	if _, _, code := runCommand("", "serve", "extra"); code == 0 {
+		t.Fatal("unexpected argument want exit code 1")
+	}
+}
+
+func TestRunInfer(t *testing.T) {
+	dir := t.TempDir()
+	writeHtmlFiles(t, dir, "sample")
+	specFile := filepath.Join(dir, "spec.txt")
+	if err := os.WriteFile(specFile, []byte("title=sample\nlinks=/a\nlinks=b\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr, code := runCommand("", "infer", "-spec", specFile, "-type", "Sample", filepath.Join(dir, "sample.html"), `heading="Hello"`)
+	if code != 0 {
+		t.Fatalf("exit %v: %v", code, stderr)
+	}
+	want := "package main\n\n" +
+		"// Sample the fields of sample.html\n" +
+		"type Sample struct {\n" +
+		"\tTitle   string   `pagser:\"title\"`\n" +
+		"\tLinks   []string `pagser:\"a->eachAttr(href)\"`\n" +
+		"\tHeading string   `pagser:\"h2\"`\n" +
+		"}\n"
+	if stdout != want {
+		t.Fatalf("want %v, but got %v", want, stdout)
+	}
+
+	//field not found
+	if _, stderr, code = runCommand("", "infer", filepath.Join(dir, "sample.html"), "title=missing"); code != 1 || !strings.Contains(stderr, "field `title`") {
+		t.Fatalf("unexpected exit %v: %v", code, stderr)
+	}
+	if _, _, code = runCommand("", "infer"); code != 1 {
+		t.Fatal("no sample want exit code 1")
+	}
+}
+
+func TestRunDiagnose(t *testing.T) {
+	dir := t.TempDir()
+	oldFile := filepath.Join(dir, "old.html")
+	newFile := filepath.Join(dir, "new.html")
+	if err := os.WriteFile(oldFile, []byte(`<title>Shop</title><h1 class="title">Pagser</h1><span class="price">19.99</span>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(newFile, []byte(`<title>Shop</title><h1 class="name">Pagser</h1><span class="price">29.99</span>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr, code := runCommand("", "diagnose", "-f", "title=title", "-f", "name=h1.title", "-f", "price=.price", oldFile, newFile)
+	if code != 1 || !strings.Contains(stderr, "2 of 3 fields are broken") {
+		t.Fatalf("unexpected exit %v: %v", code, stderr)
+	}
+	want := `FIELD  STATUS    SCORE  TAG
+name   no-match  1.00   h1.title
+                        old: "Pagser" (1 nodes)
+                 1.00   suggest: h1.name
+                 0.65   suggest: h1
+price  changed   1.00   .price
+                        old: "19.99" (1 nodes)
+                        new: "29.99" (1 nodes)
+title  ok        0.65   title
+`
+	if stdout != want {
+		t.Fatalf("want %v, but got %v", want, stdout)
+	}
+
+	stdout, _, _ = runCommand("", "diagnose", "-format", "json", "-f", "name=h1.title", oldFile, newFile)
+	if !strings.Contains(stdout, `"tag": "h1.name"`) || !strings.Contains(stdout, `"status": "no-match"`) {
+		t.Fatalf("unexpected json: %v", stdout)
+	}
+	if _, _, code = runCommand("", "diagnose", "-f", "name=h1", oldFile); code != 1 {
+		t.Fatal("missing new file want exit code 1")
+	}
+}
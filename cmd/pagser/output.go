@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/foolin/pagser"
+)
+
+// recordWriter write the parsed records
+type recordWriter interface {
+	Write(record map[string]interface{}) error
+	Flush() error
+}
+
+// newRecordWriter returns the writer of format, the json writer writes an array if multiple is true,
+// or the object of the record.
+func newRecordWriter(format string, w io.Writer, rules pagser.Rules, multiple bool) (recordWriter, error) {
+	switch format {
+	case "json":
+		return &jsonWriter{w: w, multiple: multiple}, nil
+	case "ndjson":
+		return &ndjsonWriter{encoder: json.NewEncoder(w)}, nil
+	case "csv":
+		columns := make([]string, 0, len(rules)+1)
+		if multiple {
+			columns = append(columns, sourceKey)
+		}
+		names := make([]string, 0, len(rules))
+		for name := range rules {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return &csvWriter{w: csv.NewWriter(w), columns: append(columns, names...)}, nil
+	}
+	return nil, fmt.Errorf("unknown format `%v`, it must be json, ndjson or csv", format)
+}
+
+// jsonWriter write the records as indented json
+type jsonWriter struct {
+	w        io.Writer
+	multiple bool
+	records  []interface{}
+}
+
+func (jw *jsonWriter) Write(record map[string]interface{}) error {
+	jw.records = append(jw.records, pagser.OutputJson(record))
+	return nil
+}
+
+func (jw *jsonWriter) Flush() error {
+	var v interface{} = jw.records
+	if !jw.multiple && len(jw.records) == 1 {
+		v = jw.records[0]
+	} else if jw.records == nil {
+		v = []interface{}{}
+	}
+	encoder := json.NewEncoder(jw.w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(v)
+}
+
+// ndjsonWriter write one json record per line
+type ndjsonWriter struct {
+	encoder *json.Encoder
+}
+
+func (nw *ndjsonWriter) Write(record map[string]interface{}) error {
+	nw.encoder.SetEscapeHTML(false)
+	return nw.encoder.Encode(pagser.OutputJson(record))
+}
+
+func (nw *ndjsonWriter) Flush() error {
+	return nil
+}
+
+// csvWriter write the records as csv with header, the values of lists and maps are json
+type csvWriter struct {
+	w           *csv.Writer
+	columns     []string
+	wroteHeader bool
+}
+
+func (cw *csvWriter) Write(record map[string]interface{}) error {
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+	row := make([]string, len(cw.columns))
+	for i, column := range cw.columns {
+		value := pagser.OutputJson(record[column])
+		switch v := value.(type) {
+		case nil:
+		case string:
+			row[i] = v
+		case []interface{}, map[string]interface{}:
+			data, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			row[i] = string(data)
+		default:
+			row[i] = fmt.Sprint(v)
+		}
+	}
+	return cw.w.Write(row)
+}
+
+func (cw *csvWriter) writeHeader() error {
+	if cw.wroteHeader {
+		return nil
+	}
+	cw.wroteHeader = true
+	return cw.w.Write(cw.columns)
+}
+
+func (cw *csvWriter) Flush() error {
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+	cw.w.Flush()
+	return cw.w.Error()
+}
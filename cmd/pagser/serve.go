@@ -0,0 +1,28 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/foolin/pagser/playground"
+)
+
+// listenAndServe the http server func, replaced by tests
+var listenAndServe = http.ListenAndServe
+
+// serve the playground on addr
+func serve(args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := flag.NewFlagSet("pagser serve", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	addr := flags.String("addr", "localhost:8080", "listen address of playground")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", flags.Args())
+	}
+	fmt.Fprintf(stdout, "pagser playground on http://%v/\n", *addr)
+	return listenAndServe(*addr, playground.New(newPagser()))
+}
@@ -1,28 +1,50 @@
 package pagser
 
+import "errors"
+
 const ignoreSymbol = "-"
 
+// fallbackSymbol separator of alternative selectors, eg: `pagser:"h1.title || .product-name"`
+const fallbackSymbol = "||"
+
+// ErrCast cast error, functions can wrap it when the value can not be converted,
+// the error is ignored and the field keeps zero value if Config.CastError is `false`.
+//	return nil, fmt.Errorf("%w: `%v` is not a number", pagser.ErrCast, value)
+var ErrCast = errors.New("cast error")
+
+// map field tag name suffix, eg: `pagser_key` and `pagser_value`
+const (
+	mapKeyTagSuffix   = "_key"
+	mapValueTagSuffix = "_value"
+)
+
 // Config configuration
 type Config struct {
-	TagName    string //struct tag name, default is `pagser`
-	FuncSymbol string //Function symbol, default is `->`
-	CastError  bool   //Returns an error when the type cannot be converted, default is `false`
-	Debug      bool   //Debug mode, debug will print some log, default is `false`
+	TagName         string //struct tag name, default is `pagser`
+	FuncSymbol      string //Function symbol, default is `->`
+	CastError       bool   //Returns an error when the type cannot be converted, default is `false`
+	Debug           bool   //Debug mode, debug will print some log, default is `false`, see ParseWithTrace for a structured trace
+	ContinueOnError bool   //Continue parsing after a field error, and returns ParseErrors of all failed fields, default is `false`
+	Parallelism     int    //Number of goroutines to parse the items of slice fields, default is `0`, the items are parsed sequentially
 }
 
 var defaultCfg = Config{
-	TagName:    "pagser",
-	FuncSymbol: "->",
-	CastError:  false,
-	Debug:      false,
+	TagName:         "pagser",
+	FuncSymbol:      "->",
+	CastError:       false,
+	Debug:           false,
+	ContinueOnError: false,
+	Parallelism:     0,
 }
 
 // DefaultConfig the default Config
 //	Config{
-//		TagName:    "pagser",
-//		FuncSymbol: "->",
-//		CastError:  false,
-//		Debug:      false,
+//		TagName:         "pagser",
+//		FuncSymbol:      "->",
+//		CastError:       false,
+//		Debug:           false,
+//		ContinueOnError: false,
+//		Parallelism:     0,
 //	}
 func DefaultConfig() Config {
 	return defaultCfg
@@ -0,0 +1,362 @@
+// Package crawl crawls the pages from a start page by following the link fields,
+// each page is fetched by fetch.Fetcher and parsed to the struct type of the link.
+//
+// The link fields are marked by `pagser_follow` tag with the struct type name of the linked pages:
+//	type ListPage struct {
+//		Items []struct {
+//			Link string `pagser:"a->absHref()" pagser_follow:"DetailPage"`
+//		} `pagser:".item"`
+//		Next string `pagser:"a.next->absHref()" pagser_follow:"ListPage"`
+//	}
+//
+//	c := crawl.New(fetch.New(pagser.New()))
+//	crawl.Handle(c, func(ctx context.Context, url string, page *ListPage) error { return nil })
+//	crawl.Handle(c, func(ctx context.Context, url string, page *DetailPage) error {
+//		log.Printf("%v: %v", url, page.Title)
+//		return nil
+//	})
+//	err := c.Run(ctx, "https://example.com/list", "ListPage")
+package crawl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/foolin/pagser/fetch"
+	"github.com/foolin/pagser/internal/link"
+)
+
+// followTagSuffix the tag name suffix of link fields, eg: `pagser_follow:"DetailPage"`
+const followTagSuffix = "_follow"
+
+// Config configuration
+type Config struct {
+	MaxDepth    int //maximum link depth from the start page, the start page is depth 0, `0` is unlimited, default is `3`
+	MaxPages    int //maximum pages to fetch, `0` is unlimited, default is `0`
+	Concurrency int //number of workers fetching pages at the same time, default is `4`
+}
+
+// DefaultConfig the default Config
+//	Config{
+//		MaxDepth:    3,
+//		MaxPages:    0,
+//		Concurrency: 4,
+//	}
+func DefaultConfig() Config {
+	return Config{
+		MaxDepth:    3,
+		MaxPages:    0,
+		Concurrency: 4,
+	}
+}
+
+// PageError the error of a page
+type PageError struct {
+	URL   string
+	Type  string //struct type name of the page
+	Depth int
+	Err   error
+}
+
+// Error implements error
+func (e *PageError) Error() string {
+	return fmt.Sprintf("crawl %v `%v` error: %v", e.Type, e.URL, e.Err)
+}
+
+// Unwrap returns the cause error
+func (e *PageError) Unwrap() error {
+	return e.Err
+}
+
+// Errors the errors of all failed pages, the other pages are crawled after a page error
+type Errors []*PageError
+
+// Error implements error
+func (errs Errors) Error() string {
+	lines := make([]string, 0, len(errs))
+	for _, err := range errs {
+		lines = append(lines, err.Error())
+	}
+	return fmt.Sprintf("%v page errors:\n%v", len(errs), strings.Join(lines, "\n"))
+}
+
+// Unwrap returns the page errors for errors.Is and errors.As
+func (errs Errors) Unwrap() []error {
+	list := make([]error, len(errs))
+	for i, err := range errs {
+		list[i] = err
+	}
+	return list
+}
+
+// handler the struct type and callback of pages
+type handler struct {
+	typ      reflect.Type
+	callback func(ctx context.Context, url string, page interface{}) error
+}
+
+// Crawler crawls pages by following the link fields
+type Crawler struct {
+	Config   Config
+	fetcher  *fetch.Fetcher
+	handlers map[reflect.Type]*handler //struct type => handler
+	names    map[string][]reflect.Type //type name and package qualified name => struct types
+	err      error                     //error of Handle, returned by Run
+}
+
+// New create crawler with default config
+func New(fetcher *fetch.Fetcher) *Crawler {
+	c, _ := NewWithConfig(fetcher, DefaultConfig())
+	return c
+}
+
+// NewWithConfig create crawler with Config and error
+func NewWithConfig(fetcher *fetch.Fetcher, cfg Config) (*Crawler, error) {
+	if fetcher == nil {
+		return nil, errors.New("fetcher must not nil")
+	}
+	if cfg.Concurrency < 1 {
+		return nil, errors.New("Concurrency must greater than 0")
+	}
+	return &Crawler{
+		Config:   cfg,
+		fetcher:  fetcher,
+		handlers: make(map[reflect.Type]*handler),
+		names:    make(map[string][]reflect.Type),
+	}, nil
+}
+
+// Handle register the struct type T, the pages linked by `pagser_follow:"T"` are parsed to T and passed to callback.
+// The link can be followed by the package qualified name like `pagser_follow:"detail.Page"` if the types of
+// different packages have the same name. The callback may be called concurrently. It must be called before Run,
+// the anonymous struct type can not be handled, and Run returns the error.
+func Handle[T any](c *Crawler, callback func(ctx context.Context, url string, page *T) error) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Name() == "" {
+		c.err = fmt.Errorf("anonymous type %v can not be handled", typ)
+		return
+	}
+	if _, ok := c.handlers[typ]; !ok {
+		c.names[typ.Name()] = append(c.names[typ.Name()], typ)
+		c.names[typ.String()] = append(c.names[typ.String()], typ)
+	}
+	c.handlers[typ] = &handler{
+		typ: typ,
+		callback: func(ctx context.Context, url string, page interface{}) error {
+			if callback == nil {
+				return nil
+			}
+			return callback(ctx, url, page.(*T))
+		},
+	}
+}
+
+// Run crawl from the start url parsed to the struct type of name, it returns when all the pages are crawled,
+// or the context is done. The page errors are returned as Errors after all pages.
+func (c *Crawler) Run(ctx context.Context, startURL string, name string) error {
+	if c.err != nil {
+		return c.err
+	}
+	if _, err := c.handler(name); err != nil {
+		return err
+	}
+	run := &crawlRun{
+		crawler: c,
+		ctx:     ctx,
+		visited: make(map[string]bool),
+	}
+	run.cond = sync.NewCond(&run.mu)
+	stop := context.AfterFunc(ctx, func() {
+		//wake up the waiting workers to exit
+		run.mu.Lock()
+		run.cond.Broadcast()
+		run.mu.Unlock()
+	})
+	defer stop()
+	run.enqueue(startURL, name, 0)
+	var wg sync.WaitGroup
+	for i := 0; i < c.Config.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			run.work()
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(run.errs) > 0 {
+		return run.errs
+	}
+	return nil
+}
+
+// handler returns the handler of type name or package qualified name
+func (c *Crawler) handler(name string) (*handler, error) {
+	switch types := c.names[name]; len(types) {
+	case 0:
+		return nil, fmt.Errorf("type %v is not handled", name)
+	case 1:
+		return c.handlers[types[0]], nil
+	default:
+		return nil, fmt.Errorf("type %v is ambiguous, use the package qualified name like %v", name, types[0])
+	}
+}
+
+// crawlRun the state of one Run call, the pages are crawled by Config.Concurrency workers from the queue
+type crawlRun struct {
+	crawler *Crawler
+	ctx     context.Context
+	mu      sync.Mutex
+	cond    *sync.Cond      //signaled when a task is queued, all tasks are done, or the context is done
+	queue   []crawlTask     //tasks waiting for workers
+	pending int             //tasks queued or crawling, the workers exit when it is 0
+	visited map[string]bool //url without fragment
+	errs    Errors
+}
+
+// crawlTask the page to crawl
+type crawlTask struct {
+	url   string
+	name  string
+	depth int
+}
+
+// enqueue queue the url without fragment if it is not visited and the page limit is not reached
+func (run *crawlRun) enqueue(pageURL string, name string, depth int) {
+	pageURL = link.Key(pageURL)
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	if run.visited[pageURL] || (run.crawler.Config.MaxPages > 0 && len(run.visited) >= run.crawler.Config.MaxPages) {
+		return
+	}
+	run.visited[pageURL] = true
+	run.queue = append(run.queue, crawlTask{url: pageURL, name: name, depth: depth})
+	run.pending++
+	run.cond.Signal()
+}
+
+// work crawl the queued tasks until all tasks are done or the context is done
+func (run *crawlRun) work() {
+	for {
+		run.mu.Lock()
+		for len(run.queue) == 0 && run.pending > 0 && run.ctx.Err() == nil {
+			run.cond.Wait()
+		}
+		if len(run.queue) == 0 || run.ctx.Err() != nil {
+			run.mu.Unlock()
+			return
+		}
+		task := run.queue[0]
+		run.queue = run.queue[1:]
+		run.mu.Unlock()
+
+		err := run.crawl(task.url, task.name, task.depth)
+
+		run.mu.Lock()
+		if err != nil && run.ctx.Err() == nil {
+			run.errs = append(run.errs, &PageError{URL: task.url, Type: task.name, Depth: task.depth, Err: err})
+		}
+		run.pending--
+		if run.pending == 0 {
+			run.cond.Broadcast()
+		}
+		run.mu.Unlock()
+	}
+}
+
+// crawl fetch and parse the page, call the handler and enqueue the links
+func (run *crawlRun) crawl(pageURL string, name string, depth int) error {
+	h, err := run.crawler.handler(name)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(run.ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := run.crawler.fetcher.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	page := reflect.New(h.typ)
+	if err = run.crawler.fetcher.Pagser().ParseResponseContext(run.ctx, page.Interface(), resp); err != nil {
+		return err
+	}
+	if err = h.callback(run.ctx, pageURL, page.Interface()); err != nil {
+		return err
+	}
+
+	if maxDepth := run.crawler.Config.MaxDepth; maxDepth > 0 && depth >= maxDepth {
+		return nil
+	}
+	tagName := run.crawler.fetcher.Pagser().Config.TagName + followTagSuffix
+	for _, l := range followLinks(page.Elem(), tagName) {
+		run.enqueue(link.Resolve(resp.Request.URL, l.url), l.name, depth+1)
+	}
+	return nil
+}
+
+// followLink the url of link field and the struct type name to parse
+type followLink struct {
+	url  string
+	name string
+}
+
+// followLinks returns the links of the fields with follow tag, the nested structs and slices are included
+func followLinks(value reflect.Value, tagName string) []followLink {
+	var links []followLink
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !value.IsNil() {
+			links = followLinks(value.Elem(), tagName)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			links = append(links, followLinks(value.Index(i), tagName)...)
+		}
+	case reflect.Struct:
+		typ := value.Type()
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			if name := field.Tag.Get(tagName); name != "" {
+				for _, u := range linkURLs(value.Field(i)) {
+					links = append(links, followLink{url: u, name: name})
+				}
+				continue
+			}
+			links = append(links, followLinks(value.Field(i), tagName)...)
+		}
+	}
+	return links
+}
+
+// linkURLs returns the urls of link field, it can be string, url.URL or the slice of them
+func linkURLs(value reflect.Value) []string {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		var urls []string
+		for i := 0; i < value.Len(); i++ {
+			urls = append(urls, linkURLs(value.Index(i))...)
+		}
+		return urls
+	case reflect.Ptr:
+		if value.IsNil() {
+			return nil
+		}
+	}
+	if u := strings.TrimSpace(link.String(value)); u != "" {
+		return []string{u}
+	}
+	return nil
+}
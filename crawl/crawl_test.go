@@ -0,0 +1,256 @@
+package crawl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/foolin/pagser"
+	"github.com/foolin/pagser/fetch"
+)
+
+type ListPage struct {
+	Items []struct {
+		Link string `pagser:"a->absHref()" pagser_follow:"DetailPage"`
+	} `pagser:".item"`
+	Next string `pagser:"a.next->attr(href)" pagser_follow:"ListPage"`
+}
+
+type DetailPage struct {
+	Title   string   `pagser:"h1"`
+	Related []string `pagser:".related a->eachAttr(href)" pagser_follow:"DetailPage"`
+	Back    string   `pagser:"a.back->absHref()" pagser_follow:"ListPage"`
+}
+
+// newShopServer serves 2 list pages with 3 items each, the detail pages link to the related items and back to list
+func newShopServer(inFlight *int32, maxInFlight *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if inFlight != nil {
+			n := atomic.AddInt32(inFlight, 1)
+			defer atomic.AddInt32(inFlight, -1)
+			for {
+				max := atomic.LoadInt32(maxInFlight)
+				if n <= max || atomic.CompareAndSwapInt32(maxInFlight, max, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		if len(parts) != 2 {
+			http.NotFound(w, r)
+			return
+		}
+		id, _ := strconv.Atoi(parts[1])
+		switch parts[0] {
+		case "list":
+			fmt.Fprint(w, "<html><body>")
+			for i := id*3 - 2; i <= id*3; i++ {
+				fmt.Fprintf(w, `<div class="item"><a href="/item/%v">item %v</a></div>`, i, i)
+			}
+			if id < 2 {
+				fmt.Fprintf(w, `<a class="next" href="/list/%v">next</a>`, id+1)
+			}
+			fmt.Fprint(w, "</body></html>")
+		case "item":
+			if id == 5 {
+				http.Error(w, "broken", http.StatusNotFound)
+				return
+			}
+			fmt.Fprintf(w, `<html><body><h1>Item %v</h1><div class="related"><a href="/item/%v#top">related</a></div>`+
+				`<a class="back" href="/list/1">back</a></body></html>`, id, id%6+1)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func newTestCrawler(t *testing.T, cfg Config) (*Crawler, *[]string) {
+	fetchCfg := fetch.DefaultConfig()
+	fetchCfg.MaxRetries = 0
+	fetcher, err := fetch.NewWithConfig(pagser.New(), fetchCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewWithConfig(fetcher, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	var titles []string
+	Handle(c, func(ctx context.Context, url string, page *ListPage) error {
+		return nil
+	})
+	Handle(c, func(ctx context.Context, url string, page *DetailPage) error {
+		if !strings.HasSuffix(url, strings.TrimPrefix(page.Title, "Item ")) {
+			return fmt.Errorf("unexpected title %v of %v", page.Title, url)
+		}
+		mu.Lock()
+		titles = append(titles, page.Title)
+		mu.Unlock()
+		return nil
+	})
+	return c, &titles
+}
+
+func TestCrawl(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := newShopServer(&inFlight, &maxInFlight)
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.Concurrency = 2
+	c, titles := newTestCrawler(t, cfg)
+	err := c.Run(context.Background(), server.URL+"/list/1", "ListPage")
+
+	//item 5 is broken, the others are crawled once
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 1 || !strings.HasSuffix(errs[0].URL, "/item/5") {
+		t.Fatalf("want error of item 5, but got %v", err)
+	}
+	var statusErr *fetch.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("want 404 StatusError, but got %v", err)
+	}
+	sort.Strings(*titles)
+	if strings.Join(*titles, ",") != "Item 1,Item 2,Item 3,Item 4,Item 6" {
+		t.Fatalf("unexpected titles: %v", *titles)
+	}
+	if maxInFlight > 2 {
+		t.Fatalf("want at most 2 concurrent requests, but got %v", maxInFlight)
+	}
+}
+
+func TestCrawlLimits(t *testing.T) {
+	server := newShopServer(nil, nil)
+	defer server.Close()
+
+	//depth 1: list 1 and its items
+	cfg := DefaultConfig()
+	cfg.MaxDepth = 1
+	c, titles := newTestCrawler(t, cfg)
+	if err := c.Run(context.Background(), server.URL+"/list/1", "ListPage"); err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(*titles)
+	if strings.Join(*titles, ",") != "Item 1,Item 2,Item 3" {
+		t.Fatalf("unexpected titles of depth 1: %v", *titles)
+	}
+
+	//max pages
+	cfg = DefaultConfig()
+	cfg.MaxPages = 3
+	cfg.Concurrency = 1
+	c, titles = newTestCrawler(t, cfg)
+	if err := c.Run(context.Background(), server.URL+"/list/1", "ListPage"); err != nil {
+		t.Fatal(err)
+	}
+	if len(*titles) != 2 {
+		t.Fatalf("want 2 details of 3 pages, but got %v", *titles)
+	}
+
+	//canceled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := c.Run(ctx, server.URL+"/list/1", "ListPage"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("want context canceled, but got %v", err)
+	}
+
+	//not handled
+	if err := c.Run(context.Background(), server.URL+"/list/1", "OtherPage"); err == nil {
+		t.Fatal("not handled type must return error")
+	}
+	if _, err := NewWithConfig(c.fetcher, Config{}); err == nil {
+		t.Fatal("zero concurrency must return error")
+	}
+}
+
+func TestCrawlWorkers(t *testing.T) {
+	//one list page links 200 items
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/list/1" {
+			fmt.Fprintf(w, "<html><body><h1>Item %v</h1></body></html>", strings.TrimPrefix(r.URL.Path, "/item/"))
+			return
+		}
+		fmt.Fprint(w, "<html><body>")
+		for i := 1; i <= 200; i++ {
+			fmt.Fprintf(w, `<div class="item"><a href="/item/%v">item %v</a></div>`, i, i)
+		}
+		fmt.Fprint(w, "</body></html>")
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.Concurrency = 2
+	c, titles := newTestCrawler(t, cfg)
+	base := runtime.NumGoroutine()
+	var maxGoroutines int32
+	Handle(c, func(ctx context.Context, url string, page *DetailPage) error {
+		for {
+			max, n := atomic.LoadInt32(&maxGoroutines), int32(runtime.NumGoroutine())
+			if n <= max || atomic.CompareAndSwapInt32(&maxGoroutines, max, n) {
+				break
+			}
+		}
+		return nil
+	})
+	if err := c.Run(context.Background(), server.URL+"/list/1", "ListPage"); err != nil {
+		t.Fatal(err)
+	}
+	if len(*titles) != 0 || maxGoroutines == 0 {
+		t.Fatalf("want details handled by the last handler, but got %v", *titles)
+	}
+	//the workers, http connections and server goroutines, not a goroutine per queued page
+	if n := int(maxGoroutines) - base; n > 20 {
+		t.Fatalf("want goroutines bounded by concurrency, but got %v more goroutines", n)
+	}
+}
+
+// TagInfo has the same name as pagser.TagInfo
+type TagInfo struct {
+	Title string `pagser:"h1"`
+}
+
+func TestHandleTypes(t *testing.T) {
+	server := newShopServer(nil, nil)
+	defer server.Close()
+
+	c, _ := newTestCrawler(t, DefaultConfig())
+	var titles []string
+	var mu sync.Mutex
+	Handle(c, func(ctx context.Context, url string, page *TagInfo) error {
+		mu.Lock()
+		titles = append(titles, page.Title)
+		mu.Unlock()
+		return nil
+	})
+	Handle(c, func(ctx context.Context, url string, page *pagser.TagInfo) error {
+		return errors.New("pagser.TagInfo must not be called")
+	})
+	if err := c.Run(context.Background(), server.URL+"/item/1", "TagInfo"); err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Fatalf("want ambiguous type error, but got %v", err)
+	}
+	if err := c.Run(context.Background(), server.URL+"/item/1", "crawl.TagInfo"); err != nil {
+		t.Fatal(err)
+	}
+	if len(titles) != 1 || titles[0] != "Item 1" {
+		t.Fatalf("want title of crawl.TagInfo, but got %v", titles)
+	}
+
+	Handle(c, func(ctx context.Context, url string, page *struct{}) error {
+		return nil
+	})
+	if err := c.Run(context.Background(), server.URL+"/list/1", "ListPage"); err == nil {
+		t.Fatal("anonymous type must return error")
+	}
+}
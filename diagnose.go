@@ -0,0 +1,352 @@
+package pagser
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// maxSuggestions the maximum suggestions of a field
+const maxSuggestions = 3
+
+// maxSuggestTargets the maximum elements of the old value to build the suggested selectors
+const maxSuggestTargets = 10
+
+// DiagnoseStatus the status of a field on the new page
+type DiagnoseStatus string
+
+const (
+	DiagnoseOK      DiagnoseStatus = "ok"       //same value as the old page
+	DiagnoseNoMatch DiagnoseStatus = "no-match" //selector matches nothing on the new page
+	DiagnoseChanged DiagnoseStatus = "changed"  //value is different from the old page
+	DiagnoseError   DiagnoseStatus = "error"    //invalid tag, or function error
+)
+
+// Diagnosis the result of comparing the fields on the old and new page
+type Diagnosis struct {
+	Fields []*FieldDiagnosis //fields in struct or rules order, the nested fields follow the parent field
+}
+
+// Broken returns the fields are not ok on the new page
+func (d *Diagnosis) Broken() []*FieldDiagnosis {
+	var list []*FieldDiagnosis
+	for _, field := range d.Fields {
+		if field.Status != DiagnoseOK {
+			list = append(list, field)
+		}
+	}
+	return list
+}
+
+// FieldDiagnosis the diagnosis of a field, the values are the trimmed text if the output is Selection,
+// or the items of list fields.
+type FieldDiagnosis struct {
+	Path        string         //field path, the nested fields of lists are diagnosed on the first item, eg: `Items[0].Name`
+	Tag         string         //tag value
+	Score       float64        //robustness score of selector, see SelectorScore
+	Status      DiagnoseStatus //status on the new page
+	OldMatched  int            //number of nodes matched on the old page
+	NewMatched  int            //number of nodes matched on the new page
+	OldValue    interface{}    //value on the old page, nil for the fields of nested struct
+	NewValue    interface{}    //value on the new page, nil for the fields of nested struct
+	Err         error          //error of DiagnoseError status
+	Suggestions []*Suggestion  //tags produce the old value on the new page, the most robust first
+}
+
+// Suggestion the suggested tag of a broken field
+type Suggestion struct {
+	Tag      string  //selector with the functions of the field, eg: `h1.product-name->text()`
+	Selector string  //css selector
+	Score    float64 //robustness score of selector, see SelectorScore
+	Matched  int     //number of nodes matched on the new page
+}
+
+// diagnoseField the field to diagnose of struct plan or rules
+type diagnoseField struct {
+	path   string
+	tag    *tagTokenizer
+	err    error            //invalid tag error
+	obj    reflect.Value    //struct pointer to call the struct methods, invalid for rules
+	list   bool             //the value is compared as list
+	fields []*diagnoseField //nested fields
+}
+
+// value returns the compared value of function output
+func (field *diagnoseField) value(out interface{}) interface{} {
+	if field.list {
+		return OutputList(out)
+	}
+	return OutputValue(out)
+}
+
+// Diagnose compare the fields of struct v on the old page that was parsed correctly and on the new page,
+// the fields match nothing or different values are reported with the suggested tags that produce the old values on the new page.
+// The v is a struct or pointer to struct, eg: `PageData{}`
+//	diagnosis, err := p.Diagnose(PageData{}, oldDoc.Selection, newDoc.Selection)
+//	for _, field := range diagnosis.Broken() {
+//		fmt.Println(field.Path, field.Status, field.Suggestions)
+//	}
+func (p *Pagser) Diagnose(v interface{}, oldSelection *goquery.Selection, newSelection *goquery.Selection) (*Diagnosis, error) {
+	return p.DiagnoseContext(context.Background(), v, oldSelection, newSelection)
+}
+
+// DiagnoseContext compare the fields of struct v with context, see Diagnose
+func (p *Pagser) DiagnoseContext(ctx context.Context, v interface{}, oldSelection *goquery.Selection, newSelection *goquery.Selection) (*Diagnosis, error) {
+	structType := reflect.TypeOf(v)
+	if structType != nil && structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType == nil || structType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("v must be struct or pointer to struct, but got %T", v)
+	}
+	fields := p.structDiagnoseFields(structType, "", map[reflect.Type]bool{})
+	return p.diagnose(ctx, fields, oldSelection, newSelection)
+}
+
+// DiagnoseRules compare the rules on the old and new page, see Diagnose
+func (p *Pagser) DiagnoseRules(rules Rules, oldSelection *goquery.Selection, newSelection *goquery.Selection) (*Diagnosis, error) {
+	return p.DiagnoseRulesContext(context.Background(), rules, oldSelection, newSelection)
+}
+
+// DiagnoseRulesContext compare the rules on the old and new page with context, see Diagnose
+func (p *Pagser) DiagnoseRulesContext(ctx context.Context, rules Rules, oldSelection *goquery.Selection, newSelection *goquery.Selection) (*Diagnosis, error) {
+	return p.diagnose(ctx, p.rulesDiagnoseFields(rules, ""), oldSelection, newSelection)
+}
+
+func (p *Pagser) diagnose(ctx context.Context, fields []*diagnoseField, oldSelection *goquery.Selection, newSelection *goquery.Selection) (*Diagnosis, error) {
+	oldState := p.newParseState(withDocumentBaseURL(ctx, oldSelection))
+	newState := p.newParseState(withDocumentBaseURL(ctx, newSelection))
+	diagnosis := &Diagnosis{}
+	if err := p.diagnoseFields(oldState, newState, diagnosis, fields, oldSelection, newSelection); err != nil {
+		return diagnosis, err
+	}
+	return diagnosis, nil
+}
+
+// structDiagnoseFields returns the fields of struct plan, the map fields are not diagnosed,
+// seen is the struct types of parent fields to stop the recursive types.
+func (p *Pagser) structDiagnoseFields(structType reflect.Type, path string, seen map[reflect.Type]bool) []*diagnoseField {
+	seen[structType] = true
+	defer delete(seen, structType)
+
+	obj := reflect.New(structType)
+	var fields []*diagnoseField
+	for _, plan := range p.getPlan(structType).fields {
+		field := &diagnoseField{path: joinFieldPath(path, plan.name), tag: plan.tag, err: plan.err, obj: obj}
+		if plan.err == nil {
+			typ := plan.typ
+			switch {
+			case typ.Kind() == reflect.Map:
+				continue
+			case typ.Kind() == reflect.Slice && typ.Elem().Kind() != reflect.Uint8:
+				field.list = true
+				if elemType := p.nestedStructType(typ.Elem()); elemType != nil && len(plan.tag.Funcs) == 0 && !seen[elemType] {
+					field.fields = p.structDiagnoseFields(elemType, indexFieldPath(field.path, "0"), seen)
+				}
+			default:
+				if elemType := p.nestedStructType(typ); elemType != nil && len(plan.tag.Funcs) == 0 && !seen[elemType] {
+					field.fields = p.structDiagnoseFields(elemType, field.path, seen)
+				}
+			}
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// nestedStructType returns the struct type of nested struct or pointer to struct, or nil if it is not parsed as nested struct
+func (p *Pagser) nestedStructType(t reflect.Type) reflect.Type {
+	if p.isValueType(t) || isUnmarshaler(t) {
+		return nil
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || p.isValueType(t) || isUnmarshaler(t) {
+		return nil
+	}
+	return t
+}
+
+// rulesDiagnoseFields returns the fields of rules in name order
+func (p *Pagser) rulesDiagnoseFields(rules Rules, path string) []*diagnoseField {
+	names := make([]string, 0, len(rules))
+	for name, rule := range rules {
+		if rule != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	fields := make([]*diagnoseField, 0, len(names))
+	for _, name := range names {
+		rule := rules[name]
+		field := &diagnoseField{path: joinFieldPath(path, name), list: rule.List && len(rule.Fields) == 0}
+		field.tag, field.err = p.getTag(rule.Selector)
+		if field.err != nil {
+			field.tag = &tagTokenizer{Value: rule.Selector}
+		}
+		if len(rule.Fields) > 0 {
+			fieldsPath := field.path
+			if rule.List {
+				fieldsPath = indexFieldPath(fieldsPath, "0")
+			}
+			field.fields = p.rulesDiagnoseFields(rule.Fields, fieldsPath)
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// diagnoseFields diagnose the fields on the old and new selection
+func (p *Pagser) diagnoseFields(oldState *parseState, newState *parseState, diagnosis *Diagnosis, fields []*diagnoseField, oldSelection *goquery.Selection, newSelection *goquery.Selection) error {
+	for _, field := range fields {
+		if err := newState.ctx.Err(); err != nil {
+			return err
+		}
+		result := &FieldDiagnosis{Path: field.path, Tag: field.tag.Value, Score: SelectorScore(field.tag.Selector), Status: DiagnoseOK}
+		diagnosis.Fields = append(diagnosis.Fields, result)
+		if field.err != nil {
+			result.Status, result.Err = DiagnoseError, field.err
+			continue
+		}
+
+		alt, oldOut, oldMatched, err := p.execTagAlternative(oldState, field, oldSelection)
+		if err != nil {
+			result.Status, result.Err = DiagnoseError, fmt.Errorf("old page: %w", err)
+			continue
+		}
+		_, newOut, newMatched, newErr := p.execTagAlternative(newState, field, newSelection)
+		result.OldMatched, result.NewMatched = oldMatched, newMatched
+
+		if len(field.fields) > 0 {
+			oldNode, _ := oldOut.(*goquery.Selection)
+			newNode, _ := newOut.(*goquery.Selection)
+			switch {
+			case newErr != nil:
+				result.Status, result.Err = DiagnoseError, newErr
+			case newMatched == 0 && oldMatched > 0:
+				result.Status = DiagnoseNoMatch
+			case oldNode != nil && newNode != nil && oldNode.Size() > 0 && newNode.Size() > 0:
+				if err = p.diagnoseFields(oldState, newState, diagnosis, field.fields, oldNode.First(), newNode.First()); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		result.OldValue = field.value(oldOut)
+		switch {
+		case newErr != nil:
+			result.Status, result.Err = DiagnoseError, newErr
+		case newMatched == 0 && oldMatched > 0:
+			result.Status = DiagnoseNoMatch
+		default:
+			result.NewValue = field.value(newOut)
+			if !reflect.DeepEqual(result.OldValue, result.NewValue) {
+				result.Status = DiagnoseChanged
+			}
+		}
+		if result.Status != DiagnoseOK && !isEmptyValue(result.OldValue) {
+			result.Suggestions = p.suggest(newState, field, alt, result.OldValue, newSelection)
+		}
+	}
+	return nil
+}
+
+// execTagAlternative execute the tag of field like struct parsing, and returns the used alternative of tag and fallbacks
+func (p *Pagser) execTagAlternative(state *parseState, field *diagnoseField, selection *goquery.Selection) (*tagTokenizer, interface{}, int, error) {
+	alt := field.tag
+	state.onSelect = func(tag *tagTokenizer, node *goquery.Selection) {
+		alt = tag
+	}
+	defer func() {
+		state.onSelect = nil
+	}()
+	out, matched, err := p.execTag(state, field.obj, nil, field.tag, selection)
+	return alt, out, matched, err
+}
+
+// suggest returns the tags that produce the old value on the new selection with the functions of alternative,
+// the selectors are built from the elements whose output is the old value, or an item of old list.
+func (p *Pagser) suggest(state *parseState, field *diagnoseField, alt *tagTokenizer, oldValue interface{}, selection *goquery.Selection) []*Suggestion {
+	exec := func(node *goquery.Selection) interface{} {
+		if len(alt.Funcs) == 0 {
+			return field.value(node)
+		}
+		out, err := p.execFuncs(state, field.obj, nil, alt, node)
+		if err != nil {
+			return nil
+		}
+		return field.value(out)
+	}
+	isTarget := func(node *goquery.Selection) bool {
+		value := exec(node)
+		if !field.list {
+			return reflect.DeepEqual(value, oldValue)
+		}
+		list, _ := value.([]interface{})
+		if len(list) != 1 {
+			return false
+		}
+		for _, item := range oldValue.([]interface{}) {
+			if reflect.DeepEqual(item, list[0]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	//the deepest targets first, the ancestors of a target have the same value if they have no other content
+	var targets []*goquery.Selection
+	selection.Find("*").Each(func(i int, node *goquery.Selection) {
+		if !IsIgnoredTag(goquery.NodeName(node)) && isTarget(node) {
+			targets = append(targets, node)
+		}
+	})
+	sort.SliceStable(targets, func(i, j int) bool {
+		return targets[i].Parents().Length() > targets[j].Parents().Length()
+	})
+	if len(targets) > maxSuggestTargets {
+		targets = targets[:maxSuggestTargets]
+	}
+
+	funcs := make([]string, len(alt.Funcs))
+	for i, fn := range alt.Funcs {
+		funcs[i] = fn.String()
+	}
+	seen := make(map[string]bool)
+	var suggestions []*Suggestion
+	for _, target := range targets {
+		for _, selector := range SelectorCandidates(selection, target) {
+			if seen[selector] {
+				continue
+			}
+			seen[selector] = true
+			node := selection.Find(selector)
+			if node.Length() == 0 || !reflect.DeepEqual(exec(node), oldValue) {
+				continue
+			}
+			tag := selector
+			if len(funcs) > 0 {
+				tag += p.Config.FuncSymbol + strings.Join(funcs, p.Config.FuncSymbol)
+			}
+			suggestions = append(suggestions, &Suggestion{Tag: tag, Selector: selector, Score: SelectorScore(selector), Matched: node.Length()})
+		}
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Score > suggestions[j].Score
+	})
+	//the full paths of score 0 are only suggested if there is no other suggestion
+	for len(suggestions) > 1 && suggestions[len(suggestions)-1].Score == 0 && suggestions[0].Score > 0 {
+		suggestions = suggestions[:len(suggestions)-1]
+	}
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+	return suggestions
+}
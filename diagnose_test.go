@@ -0,0 +1,148 @@
+package pagser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const rawDiagnoseOldHtml = `<html><head><title>Pagser</title></head><body>
+<h1 class="title">Pagser</h1>
+<span class="price">19.99</span>
+<a class="repo" href="https://github.com/foolin/pagser">GitHub</a>
+<ul class="tags"><li>go</li><li>html</li></ul>
+<div class="item"><h2>One</h2><a href="/one">more</a></div>
+<div class="item"><h2>Two</h2><a href="/two">more</a></div>
+</body></html>`
+
+const rawDiagnoseNewHtml = `<html><head><title>Pagser</title></head><body>
+<div id="product"><h1 class="product-name"><span>Pagser</span></h1>
+<p class="css-9x8y7z">$19.99</p>
+<span class="price">29.99</span>
+<a class="source" href="https://github.com/foolin/pagser">Source</a></div>
+<ol class="labels"><li>go</li><li>html</li></ol>
+<div class="card"><h2>One</h2><a href="/one">more</a></div>
+<div class="card"><h2>Two</h2><a href="/two">more</a></div>
+</body></html>`
+
+type DiagnoseItem struct {
+	Name string `pagser:"h2"`
+	Link string `pagser:"a->attr(href)"`
+}
+
+type DiagnoseData struct {
+	Title string          `pagser:"title"`
+	Name  string          `pagser:"h1.title"`
+	Price string          `pagser:"span.price"`
+	Repo  string          `pagser:"a.repo->attr(href)"`
+	Tags  []string        `pagser:"ul.tags li"`
+	Items []DiagnoseItem  `pagser:".item"`
+	Meta  map[string]bool `pagser:"meta"`
+}
+
+func newDiagnoseDocuments(t *testing.T) (*goquery.Document, *goquery.Document) {
+	oldDoc, err := goquery.NewDocumentFromReader(strings.NewReader(rawDiagnoseOldHtml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	newDoc, err := goquery.NewDocumentFromReader(strings.NewReader(rawDiagnoseNewHtml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return oldDoc, newDoc
+}
+
+func TestDiagnose(t *testing.T) {
+	oldDoc, newDoc := newDiagnoseDocuments(t)
+	diagnosis, err := New().Diagnose(&DiagnoseData{}, oldDoc.Selection, newDoc.Selection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := make(map[string]*FieldDiagnosis)
+	for _, field := range diagnosis.Fields {
+		fields[field.Path] = field
+	}
+	if len(diagnosis.Fields) != 6 || fields["Title"].Status != DiagnoseOK || fields["Meta"] != nil {
+		t.Fatalf("unexpected fields: %v", prettyJson(diagnosis.Fields))
+	}
+	tests := []struct {
+		path       string
+		status     DiagnoseStatus
+		suggestion string
+	}{
+		{"Name", DiagnoseNoMatch, "h1.product-name"},
+		{"Price", DiagnoseChanged, ""},
+		{"Repo", DiagnoseNoMatch, "a.source->attr(href)"},
+		{"Tags", DiagnoseNoMatch, "ol.labels li"},
+		{"Items", DiagnoseNoMatch, ""},
+	}
+	for _, tt := range tests {
+		field := fields[tt.path]
+		if field == nil || field.Status != tt.status {
+			t.Errorf("%v: want status %v, but got %v", tt.path, tt.status, prettyJson(field))
+			continue
+		}
+		if tt.suggestion == "" {
+			if len(field.Suggestions) > 0 {
+				t.Errorf("%v: unexpected suggestions %v", tt.path, prettyJson(field.Suggestions))
+			}
+			continue
+		}
+		if len(field.Suggestions) == 0 || field.Suggestions[0].Tag != tt.suggestion {
+			t.Errorf("%v: want suggestion %v, but got %v", tt.path, tt.suggestion, prettyJson(field.Suggestions))
+		}
+	}
+	if fields["Price"].OldValue != "19.99" || fields["Price"].NewValue != "29.99" {
+		t.Errorf("unexpected values of Price: %v", prettyJson(fields["Price"]))
+	}
+	if len(diagnosis.Broken()) != 5 {
+		t.Errorf("want 5 broken fields, but got %v", len(diagnosis.Broken()))
+	}
+	if _, err = New().Diagnose("x", oldDoc.Selection, newDoc.Selection); err == nil {
+		t.Error("not struct want error")
+	}
+}
+
+func TestDiagnoseRules(t *testing.T) {
+	oldDoc, newDoc := newDiagnoseDocuments(t)
+	rules := Rules{
+		"items": {Selector: "div:has(h2)", List: true, Fields: Rules{
+			"name": {Selector: "h2"},
+			"link": {Selector: "a.more->attr(href)"},
+		}},
+		"bad": {Selector: "a->attr("},
+	}
+	diagnosis, err := New().DiagnoseRules(rules, oldDoc.Selection, newDoc.Selection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := make([]string, len(diagnosis.Fields))
+	for i, field := range diagnosis.Fields {
+		paths[i] = field.Path + ":" + string(field.Status)
+	}
+	if strings.Join(paths, ",") != "bad:error,items:ok,items[0].link:ok,items[0].name:ok" {
+		t.Fatalf("unexpected fields: %v", paths)
+	}
+}
+
+func TestSelectorScore(t *testing.T) {
+	tests := []struct {
+		selector string
+		score    float64
+	}{
+		{"#main", 1},
+		{"#main h1.title", 0.9},
+		{"h1", 0.65},
+		{"ul.tags > li:nth-of-type(2)", 0.55},
+		{"p.css-9x8y7z", 0.8},
+		{"div > ul:nth-of-type(2) > li", 0},
+		{"xpath://h1", 0.5},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if score := SelectorScore(tt.selector); score != tt.score {
+			t.Errorf("%v: want score %v, but got %v", tt.selector, tt.score, score)
+		}
+	}
+}
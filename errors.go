@@ -0,0 +1,107 @@
+package pagser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FieldError the parse error of a field
+//	var fieldErr *pagser.FieldError
+//	if errors.As(err, &fieldErr) {
+//		log.Printf("field %v selector %v error: %v", fieldErr.Path, fieldErr.Selector, fieldErr.Err)
+//	}
+type FieldError struct {
+	Path     string //field path of struct, eg: `Items[3].Price`
+	Tag      string //struct tag value, eg: `.price->attr(content)`
+	Selector string //tag selector, eg: `.price`
+	Func     string //tag functions, eg: `attr(content)->trim()`
+	Err      error  //the cause error
+}
+
+func newFieldError(path string, tag *tagTokenizer, err error) *FieldError {
+	fieldErr := &FieldError{
+		Path: path,
+		Err:  err,
+	}
+	if tag != nil {
+		fieldErr.Tag = tag.Value
+		fieldErr.Selector = tag.Selector
+		fieldErr.Func = tag.funcsString()
+	}
+	return fieldErr
+}
+
+// Error implements error
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("field `%v` tag=`%v` error: %v", e.Path, e.Tag, e.Err)
+}
+
+// Unwrap returns the cause error
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ParseErrors the errors of all failed fields, returned if Config.ContinueOnError is `true`
+//	var parseErrs pagser.ParseErrors
+//	if errors.As(err, &parseErrs) {
+//		for _, fieldErr := range parseErrs {
+//			log.Printf("field %v error: %v", fieldErr.Path, fieldErr.Err)
+//		}
+//	}
+type ParseErrors []*FieldError
+
+// Error implements error
+func (errs ParseErrors) Error() string {
+	lines := make([]string, 0, len(errs))
+	for _, err := range errs {
+		lines = append(lines, err.Error())
+	}
+	return fmt.Sprintf("%v field errors:\n%v", len(errs), strings.Join(lines, "\n"))
+}
+
+// Unwrap returns the field errors for errors.Is and errors.As
+func (errs ParseErrors) Unwrap() []error {
+	list := make([]error, len(errs))
+	for i, err := range errs {
+		list[i] = err
+	}
+	return list
+}
+
+// ValidationError the field value does not pass the validation option of `pagser_opts` tag,
+// it is the cause error of FieldError:
+//	var validErr *pagser.ValidationError
+//	if errors.As(err, &validErr) {
+//		log.Printf("page is broken: %v", validErr)
+//	}
+type ValidationError struct {
+	Rule    string //validation rule, eg: `required`, `min`, `max`, `regex`, `oneof`
+	Param   string //rule param, eg: `1` of `min=1`
+	Value   string //the invalid value of `regex` and `oneof` rules
+	Matched int    //the number of nodes matched by selector
+}
+
+// Error implements error
+func (e *ValidationError) Error() string {
+	rule := e.Rule
+	if e.Param != "" {
+		rule += "=" + e.Param
+	}
+	if e.Value != "" {
+		return fmt.Sprintf("validation `%v` failed: invalid value `%v`", rule, e.Value)
+	}
+	return fmt.Sprintf("validation `%v` failed: matched %v nodes", rule, e.Matched)
+}
+
+// joinFieldPath join the field name to struct path
+func joinFieldPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// indexFieldPath join the slice index or map key to struct path, eg: `Items[3]`
+func indexFieldPath(path string, index string) string {
+	return path + "[" + index + "]"
+}
@@ -0,0 +1,96 @@
+package pagser
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const rawErrorsHtml = `
+<html>
+<body>
+	<h1>Title</h1>
+	<ul>
+		<li><span class="price">1.5</span></li>
+		<li><span class="price">abc</span></li>
+		<li><span class="price">2.5</span></li>
+		<li><span class="price">xyz</span></li>
+	</ul>
+	<span class="count">many</span>
+</body>
+</html>
+`
+
+type ErrorsData struct {
+	Title string `pagser:"h1"`
+	Items []struct {
+		Price float64 `pagser:".price"`
+	} `pagser:"ul li"`
+	Count  int    `pagser:".count"`
+	Func   string `pagser:"h1->NotExists()"`
+	Method string `pagser:"h1->Fail()"`
+	After  string `pagser:"h1->toUpper()"`
+}
+
+var errFailMethod = errors.New("fail method")
+
+func (d ErrorsData) Fail(node *goquery.Selection, args ...string) (out interface{}, err error) {
+	return nil, errFailMethod
+}
+
+func TestParseErrors(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.CastError = true
+	cfg.ContinueOnError = true
+	p, err := NewWithConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var data ErrorsData
+	err = p.Parse(&data, rawErrorsHtml)
+	if err == nil {
+		t.Fatal("want errors")
+	}
+	var parseErrs ParseErrors
+	if !errors.As(err, &parseErrs) {
+		t.Fatalf("error want ParseErrors, but got %T", err)
+	}
+	paths := []string{"Items[1].Price", "Items[3].Price", "Count", "Func", "Method"}
+	if len(parseErrs) != len(paths) {
+		t.Fatalf("want %v errors, but got %v", len(paths), err)
+	}
+	for i, path := range paths {
+		if parseErrs[i].Path != path {
+			t.Errorf("error %v path want `%v`, but got `%v`", i, path, parseErrs[i].Path)
+		}
+	}
+	if parseErrs[3].Selector != "h1" || parseErrs[3].Func != "NotExists()" {
+		t.Errorf("unexpected field error: %#v", parseErrs[3])
+	}
+	if !errors.Is(err, errFailMethod) {
+		t.Error("errors.Is want match method error")
+	}
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Path != "Items[1].Price" {
+		t.Errorf("errors.As want the first FieldError, but got %v", fieldErr)
+	}
+	//continue parse the other fields
+	if data.Title != "Title" || data.After != "TITLE" || len(data.Items) != 4 || data.Items[2].Price != 2.5 {
+		t.Errorf("other fields want parsed, but got %v", prettyJson(data))
+	}
+
+	cfg.ContinueOnError = false
+	p, err = NewWithConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = p.Parse(&ErrorsData{}, rawErrorsHtml)
+	if !errors.As(err, &fieldErr) || fieldErr.Path != "Items[1].Price" {
+		t.Errorf("error want the first FieldError, but got %v", err)
+	}
+	if errors.As(err, &parseErrs) {
+		t.Error("error must not be ParseErrors if not continue on error")
+	}
+}
@@ -0,0 +1,62 @@
+package pagser
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Evaluation the result of evaluating a tag expression, with the output of each stage
+type Evaluation struct {
+	Tag          *TagInfo       //parsed tag expression
+	Alternatives []*Alternative //the tried alternatives of tag and fallbacks in order
+	Matched      int            //number of nodes matched by the selector of the used alternative
+	Output       interface{}    //output of the used alternative, a Selection if it has no functions
+}
+
+// Alternative the evaluation of the tag or a fallback alternative
+type Alternative struct {
+	Selector string             //selector, with `xpath:` prefix if it is xpath
+	Nodes    *goquery.Selection //nodes matched by selector
+	Stages   []*Stage           //output of each function
+}
+
+// Stage the output of a function in pipeline
+type Stage struct {
+	Func   string      //function call, eg: `attr(href)`
+	Output interface{} //output of the function
+	Err    error       //error of the function
+}
+
+// Value returns the value of output, it is the trimmed text if output is Selection
+func (eval *Evaluation) Value() interface{} {
+	return OutputValue(eval.Output)
+}
+
+// Evaluate execute the tag expression on selection like a struct field, and returns the output of each stage.
+// The evaluation is returned with the error of the failed stage.
+//	eval, err := p.Evaluate(doc.Selection, ".item a->attr(href) || .item->text()")
+func (p *Pagser) Evaluate(selection *goquery.Selection, expr string) (*Evaluation, error) {
+	return p.EvaluateContext(context.Background(), selection, expr)
+}
+
+// EvaluateContext execute the tag expression with context, see Evaluate.
+// The expression is not cached like struct tags, it can be user input.
+func (p *Pagser) EvaluateContext(ctx context.Context, selection *goquery.Selection, expr string) (*Evaluation, error) {
+	tag, err := p.newTag(expr)
+	if err != nil {
+		return nil, err
+	}
+	eval := &Evaluation{Tag: newTagInfo(tag)}
+	state := p.newParseState(withDocumentBaseURL(ctx, selection))
+	state.onSelect = func(tag *tagTokenizer, node *goquery.Selection) {
+		eval.Alternatives = append(eval.Alternatives, &Alternative{Selector: tag.Selector, Nodes: node})
+	}
+	state.onFunc = func(fn *tagFunc, out interface{}, err error) {
+		alt := eval.Alternatives[len(eval.Alternatives)-1]
+		alt.Stages = append(alt.Stages, &Stage{Func: fn.String(), Output: out, Err: err})
+	}
+	eval.Output, eval.Matched, err = p.execTag(state, reflect.Value{}, nil, tag, selection)
+	return eval, err
+}
@@ -0,0 +1,52 @@
+package pagser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestEvaluate(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(rawPagserHtml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := New()
+
+	//first alternative is empty, fallback is used
+	eval, err := p.Evaluate(doc.Selection, ".missing->text() || title->text()->toUpper()")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(eval.Alternatives) != 2 || eval.Alternatives[0].Nodes.Size() != 0 || eval.Matched != 1 {
+		t.Fatalf("unexpected alternatives: %v", prettyJson(eval.Tag))
+	}
+	stages := eval.Alternatives[1].Stages
+	if len(stages) != 2 || stages[0].Func != "text()" || stages[0].Output != "Pagser Example" || stages[1].Output != "PAGSER EXAMPLE" {
+		t.Fatalf("unexpected stages: %#v", stages)
+	}
+	if eval.Value() != "PAGSER EXAMPLE" {
+		t.Fatalf("want PAGSER EXAMPLE, but got %v", eval.Value())
+	}
+
+	//no functions, output is the nodes
+	if eval, err = p.Evaluate(doc.Selection, "title"); err != nil || eval.Value() != "Pagser Example" {
+		t.Fatalf("unexpected value %v, error %v", eval.Value(), err)
+	}
+
+	//failed stage is returned with error
+	eval, err = p.Evaluate(doc.Selection, "title->nope()")
+	if err == nil || len(eval.Alternatives[0].Stages) != 1 || eval.Alternatives[0].Stages[0].Err == nil {
+		t.Fatalf("want error of nope(), but got %v", err)
+	}
+	if _, err = p.Evaluate(doc.Selection, "title->attr("); err == nil {
+		t.Fatal("invalid tag want error")
+	}
+
+	//expressions are not cached
+	p.mapTags.Range(func(key, value interface{}) bool {
+		t.Errorf("expression %v must not be cached", key)
+		return true
+	})
+}
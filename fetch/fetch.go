@@ -0,0 +1,292 @@
+// Package fetch fetches pages over http and parses them to struct by pagser,
+// with per-host rate limits, retry with backoff, cookies, custom headers and response size limits.
+//
+//	fetcher := fetch.New(pagser.New())
+//	var data PageData
+//	err := fetcher.Get(ctx, "https://example.com/", &data)
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/foolin/pagser"
+)
+
+// ErrBodyTooLarge the response body is larger than Config.MaxBodySize
+var ErrBodyTooLarge = errors.New("fetch: response body too large")
+
+// StatusError the response status is not 2xx
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Status     string
+}
+
+// Error implements error
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("fetch: %v %v: %v", e.Method, e.URL, e.Status)
+}
+
+// Config configuration
+type Config struct {
+	Client       *http.Client   //http client, default is a client with 30s timeout
+	Jar          http.CookieJar //cookie jar if the client has no jar, default is a new cookiejar
+	Header       http.Header    //headers of each request, the request headers are not replaced
+	RateLimit    time.Duration  //minimum interval between the requests to the same host, default is `0`
+	MaxRetries   int            //retries on network errors, 5xx and 429 status, default is `3`
+	RetryWait    time.Duration  //wait before the first retry, and doubled for each retry, default is `500ms`
+	MaxRetryWait time.Duration  //maximum wait before a retry, including Retry-After header, default is `30s`
+	MaxBodySize  int64          //maximum response body size, `0` is unlimited, default is `10MB`
+}
+
+// DefaultConfig the default Config
+//	Config{
+//		Header:       http.Header{"User-Agent": {"Mozilla/5.0 (compatible; pagser)"}},
+//		MaxRetries:   3,
+//		RetryWait:    500 * time.Millisecond,
+//		MaxRetryWait: 30 * time.Second,
+//		MaxBodySize:  10 << 20,
+//	}
+func DefaultConfig() Config {
+	return Config{
+		Header:       http.Header{"User-Agent": {"Mozilla/5.0 (compatible; pagser)"}},
+		MaxRetries:   3,
+		RetryWait:    500 * time.Millisecond,
+		MaxRetryWait: 30 * time.Second,
+		MaxBodySize:  10 << 20,
+	}
+}
+
+// Fetcher fetches pages and parses them by pagser, it is safe for concurrent use
+type Fetcher struct {
+	Config Config
+	pagser *pagser.Pagser
+	client *http.Client
+	mu     sync.Mutex
+	hosts  map[string]time.Time //host => time of next request
+}
+
+// New create fetcher with default config
+func New(p *pagser.Pagser) *Fetcher {
+	f, _ := NewWithConfig(p, DefaultConfig())
+	return f
+}
+
+// NewWithConfig create fetcher with Config and error
+func NewWithConfig(p *pagser.Pagser, cfg Config) (*Fetcher, error) {
+	if p == nil {
+		return nil, errors.New("pagser must not nil")
+	}
+	if cfg.MaxRetries < 0 {
+		return nil, errors.New("MaxRetries must not negative")
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	if cfg.Client != nil {
+		copied := *cfg.Client
+		client = &copied
+	}
+	if client.Jar == nil {
+		client.Jar = cfg.Jar
+		if client.Jar == nil {
+			client.Jar, _ = cookiejar.New(nil)
+		}
+	}
+	return &Fetcher{
+		Config: cfg,
+		pagser: p,
+		client: client,
+		hosts:  make(map[string]time.Time),
+	}, nil
+}
+
+// Pagser returns the pagser to parse pages
+func (f *Fetcher) Pagser() *pagser.Pagser {
+	return f.pagser
+}
+
+// Client returns the http client with cookie jar
+func (f *Fetcher) Client() *http.Client {
+	return f.client
+}
+
+// Get fetch the url and parse the page to v
+func (f *Fetcher) Get(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	return f.Parse(req, v)
+}
+
+// Parse send the request and parse the page to v, the charset and base url are taken from the response
+func (f *Fetcher) Parse(req *http.Request, v interface{}) error {
+	resp, err := f.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return f.pagser.ParseResponseContext(req.Context(), v, resp)
+}
+
+// Do send the request with rate limit and retries, the response status is 2xx or 3xx,
+// otherwise a StatusError is returned. The response body must be closed.
+func (f *Fetcher) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	canRetry := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	for attempt := 0; ; attempt++ {
+		if err := f.wait(ctx, req.URL.Host); err != nil {
+			return nil, err
+		}
+		resp, err := f.send(req, attempt)
+		retry := attempt < f.Config.MaxRetries && canRetry && ctx.Err() == nil
+		if err != nil {
+			if !retry {
+				return nil, err
+			}
+		} else if resp.StatusCode < 400 {
+			return f.limitBody(resp)
+		} else if !retry || !retryStatus(resp.StatusCode) {
+			resp.Body.Close()
+			return nil, &StatusError{Method: req.Method, URL: req.URL.String(), StatusCode: resp.StatusCode, Status: resp.Status}
+		}
+
+		wait := f.backoff(attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+				wait = after
+			}
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+		if wait > f.Config.MaxRetryWait && f.Config.MaxRetryWait > 0 {
+			wait = f.Config.MaxRetryWait
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// send clone the request with config headers and body, and send it
+func (f *Fetcher) send(req *http.Request, attempt int) (*http.Response, error) {
+	sendReq := req.Clone(req.Context())
+	for name, values := range f.Config.Header {
+		if _, ok := sendReq.Header[name]; !ok {
+			sendReq.Header[name] = values
+		}
+	}
+	if attempt > 0 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		sendReq.Body = body
+	}
+	return f.client.Do(sendReq)
+}
+
+// wait reserve the next request time of host, and wait until it
+func (f *Fetcher) wait(ctx context.Context, host string) error {
+	if f.Config.RateLimit <= 0 {
+		return ctx.Err()
+	}
+	f.mu.Lock()
+	now := time.Now()
+	next := f.hosts[host]
+	if next.Before(now) {
+		next = now
+	}
+	f.hosts[host] = next.Add(f.Config.RateLimit)
+	f.mu.Unlock()
+	return sleep(ctx, next.Sub(now))
+}
+
+// backoff the wait before retry, it is doubled for each attempt
+func (f *Fetcher) backoff(attempt int) time.Duration {
+	wait := f.Config.RetryWait
+	for i := 0; i < attempt && (f.Config.MaxRetryWait <= 0 || wait < f.Config.MaxRetryWait); i++ {
+		wait *= 2
+	}
+	return wait
+}
+
+// limitBody returns ErrBodyTooLarge if the body is larger than Config.MaxBodySize
+func (f *Fetcher) limitBody(resp *http.Response) (*http.Response, error) {
+	if f.Config.MaxBodySize <= 0 {
+		return resp, nil
+	}
+	if resp.ContentLength > f.Config.MaxBodySize {
+		resp.Body.Close()
+		return nil, ErrBodyTooLarge
+	}
+	resp.Body = &limitedBody{ReadCloser: resp.Body, remaining: f.Config.MaxBodySize}
+	return resp, nil
+}
+
+// limitedBody the body returns ErrBodyTooLarge after reading the limit
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, ErrBodyTooLarge
+	}
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		return n, ErrBodyTooLarge
+	}
+	return n, err
+}
+
+// retryStatus the status can be retried
+func retryStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+// retryAfter parse Retry-After header, it is seconds or http date
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+// sleep wait for duration or the context is done
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
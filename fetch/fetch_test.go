@@ -0,0 +1,192 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/foolin/pagser"
+)
+
+type PageData struct {
+	Title string `pagser:"title"`
+	Next  string `pagser:"a.next->absHref()"`
+}
+
+const rawPageHtml = `<html><head><title>%v</title></head><body><a class="next" href="/page/2">next</a></body></html>`
+
+func newTestFetcher(t *testing.T, cfg Config) *Fetcher {
+	cfg.RetryWait = time.Millisecond
+	f, err := NewWithConfig(pagser.New(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestFetcherGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, rawPageHtml, r.Header.Get("User-Agent")+"|"+r.Header.Get("X-Token"))
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.Header.Set("X-Token", "abc")
+	f := newTestFetcher(t, cfg)
+	var data PageData
+	if err := f.Get(context.Background(), server.URL+"/page/1", &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Title != "Mozilla/5.0 (compatible; pagser)|abc" {
+		t.Fatalf("unexpected title: %v", data.Title)
+	}
+	if data.Next != server.URL+"/page/2" {
+		t.Fatalf("unexpected next: %v", data.Next)
+	}
+
+	//request headers are not replaced
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("User-Agent", "custom")
+	if err := f.Parse(req, &data); err != nil || data.Title != "custom|abc" {
+		t.Fatalf("unexpected title: %v %v", data.Title, err)
+	}
+}
+
+func TestFetcherRetry(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&hits, 1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			fmt.Fprintf(w, rawPageHtml, "ok")
+		}
+	}))
+	defer server.Close()
+
+	f := newTestFetcher(t, DefaultConfig())
+	var data PageData
+	if err := f.Get(context.Background(), server.URL, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Title != "ok" || hits != 3 {
+		t.Fatalf("want title ok after 3 hits, but got %v after %v hits", data.Title, hits)
+	}
+
+	//retries exhausted
+	atomic.StoreInt32(&hits, 0)
+	cfg := DefaultConfig()
+	cfg.MaxRetries = 1
+	f = newTestFetcher(t, cfg)
+	var statusErr *StatusError
+	err := f.Get(context.Background(), server.URL, &data)
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusTooManyRequests || hits != 2 {
+		t.Fatalf("want 429 StatusError after 2 hits, but got %v after %v hits", err, hits)
+	}
+}
+
+func TestFetcherNotRetry(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if r.URL.Path == "/slow" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	f := newTestFetcher(t, DefaultConfig())
+	var statusErr *StatusError
+	if err := f.Get(context.Background(), server.URL, &PageData{}); !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound || hits != 1 {
+		t.Fatalf("want 404 StatusError after 1 hit, but got %v after %v hits", err, hits)
+	}
+
+	//context is canceled while waiting for retry
+	cfg := DefaultConfig()
+	f = newTestFetcher(t, cfg)
+	f.Config.RetryWait = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := f.Get(ctx, server.URL+"/slow", &PageData{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want deadline exceeded, but got %v", err)
+	}
+}
+
+func TestFetcherRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, rawPageHtml, "ok")
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.RateLimit = 30 * time.Millisecond
+	f := newTestFetcher(t, cfg)
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := f.Get(context.Background(), server.URL, &PageData{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Fatalf("want at least 90ms for 4 requests, but got %v", elapsed)
+	}
+}
+
+func TestFetcherCookies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "s1", Path: "/"})
+		}
+		session := "none"
+		if cookie, err := r.Cookie("session"); err == nil {
+			session = cookie.Value
+		}
+		fmt.Fprintf(w, rawPageHtml, session)
+	}))
+	defer server.Close()
+
+	f := newTestFetcher(t, DefaultConfig())
+	var data PageData
+	if err := f.Get(context.Background(), server.URL+"/login", &data); err != nil || data.Title != "none" {
+		t.Fatalf("unexpected title: %v %v", data.Title, err)
+	}
+	if err := f.Get(context.Background(), server.URL+"/home", &data); err != nil || data.Title != "s1" {
+		t.Fatalf("want session cookie, but got %v %v", data.Title, err)
+	}
+}
+
+func TestFetcherMaxBodySize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/chunked" {
+			w.(http.Flusher).Flush()
+		}
+		fmt.Fprintf(w, rawPageHtml, strings.Repeat("x", 1024))
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.MaxBodySize = 512
+	f := newTestFetcher(t, cfg)
+	for _, path := range []string{"/", "/chunked"} {
+		if err := f.Get(context.Background(), server.URL+path, &PageData{}); !errors.Is(err, ErrBodyTooLarge) {
+			t.Fatalf("%v: want ErrBodyTooLarge, but got %v", path, err)
+		}
+	}
+
+	cfg.MaxBodySize = 0
+	f = newTestFetcher(t, cfg)
+	if err := f.Get(context.Background(), server.URL+"/chunked", &PageData{}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,176 @@
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/foolin/pagser"
+	"github.com/foolin/pagser/internal/link"
+)
+
+// nextTagSuffix the tag name suffix of next page field, eg: `pagser_next:"true"`
+const nextTagSuffix = "_next"
+
+// Pagination the options of following next page links
+//	type ListPage struct {
+//		Items []Item `pagser:".item"`
+//		Next  string `pagser:"a.next->absHref()" pagser_next:"true"`
+//	}
+type Pagination struct {
+	Next     string //tag of next page url, eg: `a.next->absHref()`, default is the field with `pagser_next:"true"`
+	MaxPages int    //maximum pages to fetch, `0` is unlimited
+}
+
+// Page the parsed page of pagination
+type Page struct {
+	Index int         //page index, starts from 0
+	URL   string      //page url
+	Value interface{} //pointer of parsed struct
+	Err   error       //fetch or parse error, it is the last page if Err is not nil
+}
+
+// Paginate fetch the page of url and the next pages, parse each page to the struct of v,
+// and append the slice fields of each page to v, the other fields are the values of first page.
+// It stops at the last page, Pagination.MaxPages, a repeated url or the context is done,
+// v has the merged pages before the error if an error is returned.
+func (f *Fetcher) Paginate(ctx context.Context, url string, v interface{}, pagination Pagination) error {
+	refValue := reflect.ValueOf(v)
+	if refValue.Kind() != reflect.Ptr || refValue.IsNil() || refValue.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%T is not pointer of struct", v)
+	}
+	return f.paginate(ctx, url, refValue.Elem().Type(), pagination, func(page *Page) error {
+		if page.Err != nil {
+			return page.Err
+		}
+		mergePage(refValue.Elem(), reflect.ValueOf(page.Value).Elem(), page.Index == 0)
+		return nil
+	})
+}
+
+// PaginateChan fetch the pages like Paginate, and send each page to the channel, v is used as the struct type only.
+// The channel is closed after the last page, the page with error is the last page.
+// Cancel the context to stop fetching if the channel is not read to the end.
+func (f *Fetcher) PaginateChan(ctx context.Context, url string, v interface{}, pagination Pagination) <-chan *Page {
+	pages := make(chan *Page)
+	go func() {
+		defer close(pages)
+		refType := reflect.TypeOf(v)
+		if refType == nil || refType.Kind() != reflect.Ptr || refType.Elem().Kind() != reflect.Struct {
+			pages <- &Page{URL: url, Err: fmt.Errorf("%T is not pointer of struct", v)}
+			return
+		}
+		f.paginate(ctx, url, refType.Elem(), pagination, func(page *Page) error {
+			select {
+			case pages <- page:
+				return page.Err
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+	return pages
+}
+
+// paginate fetch and parse the pages, and call fn for each page until fn returns an error
+func (f *Fetcher) paginate(ctx context.Context, url string, structType reflect.Type, pagination Pagination, fn func(page *Page) error) error {
+	nextField := -1
+	if pagination.Next == "" {
+		if nextField = f.nextField(structType); nextField < 0 {
+			return fn(&Page{URL: url, Err: fmt.Errorf("%v has no field with `%v%v:\"true\"` tag, and Pagination.Next is empty",
+				structType, f.pagser.Config.TagName, nextTagSuffix)})
+		}
+	}
+	visited := make(map[string]bool)
+	for index := 0; url != "" && (pagination.MaxPages <= 0 || index < pagination.MaxPages); index++ {
+		key := link.Key(url)
+		if visited[key] {
+			break
+		}
+		visited[key] = true
+
+		page := &Page{Index: index, URL: url}
+		if page.Err = ctx.Err(); page.Err != nil {
+			return fn(page)
+		}
+		value := reflect.New(structType)
+		page.Value = value.Interface()
+		var doc *goquery.Document
+		doc, page.Err = f.getDocument(ctx, url)
+		if page.Err == nil {
+			page.Err = f.pagser.ParseDocumentContext(ctx, page.Value, doc)
+		}
+		if page.Err == nil {
+			var next string
+			if nextField >= 0 {
+				next = link.String(value.Elem().Field(nextField))
+			} else {
+				next, page.Err = f.nextURL(ctx, doc, pagination.Next)
+			}
+			url = link.Resolve(doc.Url, next)
+		}
+		if page.Err != nil {
+			page.Err = fmt.Errorf("page %v %v: %w", index, page.URL, page.Err)
+		}
+		if err := fn(page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getDocument fetch the url to document, the url of document is the response request url
+func (f *Fetcher) getDocument(ctx context.Context, url string) (*goquery.Document, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	doc, err := pagser.NewDocument(resp.Body, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+	doc.Url = resp.Request.URL
+	return doc, nil
+}
+
+// nextURL parse the next page url of document by tag
+func (f *Fetcher) nextURL(ctx context.Context, doc *goquery.Document, tag string) (string, error) {
+	ctx = pagser.WithBaseURL(ctx, doc.Url)
+	result, err := f.pagser.ParseSelectionWithRulesContext(ctx, doc.Selection, pagser.Rules{"next": {Selector: tag}})
+	if err != nil {
+		return "", err
+	}
+	return link.String(reflect.ValueOf(result["next"])), nil
+}
+
+// nextField returns the index of the field with `pagser_next:"true"` tag, or -1
+func (f *Fetcher) nextField(structType reflect.Type) int {
+	tagName := f.pagser.Config.TagName + nextTagSuffix
+	for i := 0; i < structType.NumField(); i++ {
+		if structType.Field(i).Tag.Get(tagName) == "true" {
+			return i
+		}
+	}
+	return -1
+}
+
+// mergePage append the slice fields of page to dst, the other fields are set if it is the first page
+func mergePage(dst reflect.Value, page reflect.Value, first bool) {
+	if first {
+		dst.Set(page)
+		return
+	}
+	for i := 0; i < dst.NumField(); i++ {
+		field := dst.Field(i)
+		if field.Kind() == reflect.Slice && field.CanSet() {
+			field.Set(reflect.AppendSlice(field, page.Field(i)))
+		}
+	}
+}
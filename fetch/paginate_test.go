@@ -0,0 +1,148 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+type ListItem struct {
+	Name string `pagser:"->text()"`
+}
+
+type ListPage struct {
+	Title string     `pagser:"h1"`
+	Page  int        `pagser:".page"`
+	Items []ListItem `pagser:".item"`
+	Next  string     `pagser:"a.next->absHref()" pagser_next:"true"`
+}
+
+type ListPageNoNext struct {
+	Page  int        `pagser:".page"`
+	Items []ListItem `pagser:".item"`
+}
+
+// newListServer serves pages /list/1 to /list/last, the last page links to loopTo if it is not 0
+func newListServer(last int, loopTo int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, err := strconv.Atoi(r.URL.Path[len("/list/"):])
+		if err != nil || page < 1 || page > last {
+			http.NotFound(w, r)
+			return
+		}
+		next := ""
+		if page < last {
+			next = fmt.Sprintf(`<a class="next" href="%v">next</a>`, page+1)
+		} else if loopTo > 0 {
+			next = fmt.Sprintf(`<a class="next" href="/list/%v#top">next</a>`, loopTo)
+		}
+		fmt.Fprintf(w, `<html><body><h1>List %v</h1><span class="page">%v</span>`+
+			`<div class="item">item %v-a</div><div class="item">item %v-b</div>%v</body></html>`, page, page, page, page, next)
+	}))
+}
+
+func TestPaginate(t *testing.T) {
+	server := newListServer(3, 0)
+	defer server.Close()
+	f := newTestFetcher(t, DefaultConfig())
+
+	var data ListPage
+	if err := f.Paginate(context.Background(), server.URL+"/list/1", &data, Pagination{}); err != nil {
+		t.Fatal(err)
+	}
+	if data.Title != "List 1" || data.Page != 1 || len(data.Items) != 6 || data.Items[5].Name != "item 3-b" {
+		t.Fatalf("unexpected data: %+v", data)
+	}
+
+	//max pages
+	data = ListPage{}
+	if err := f.Paginate(context.Background(), server.URL+"/list/1", &data, Pagination{MaxPages: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Items) != 4 || data.Items[3].Name != "item 2-b" {
+		t.Fatalf("want 2 pages, but got %+v", data.Items)
+	}
+
+	//next selector
+	var noNext ListPageNoNext
+	if err := f.Paginate(context.Background(), server.URL+"/list/2", &noNext, Pagination{Next: "a.next->attr(href)"}); err != nil {
+		t.Fatal(err)
+	}
+	if noNext.Page != 2 || len(noNext.Items) != 4 {
+		t.Fatalf("unexpected data: %+v", noNext)
+	}
+	if err := f.Paginate(context.Background(), server.URL+"/list/1", &noNext, Pagination{}); err == nil {
+		t.Fatal("struct without next field must return error")
+	}
+}
+
+func TestPaginateStop(t *testing.T) {
+	//page 4 links back to page 2
+	server := newListServer(4, 2)
+	defer server.Close()
+	f := newTestFetcher(t, DefaultConfig())
+
+	var data ListPage
+	if err := f.Paginate(context.Background(), server.URL+"/list/1", &data, Pagination{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Items) != 8 {
+		t.Fatalf("want 4 pages, but got %+v", data.Items)
+	}
+
+	//error page keeps merged pages
+	errServer := newListServer(2, 3)
+	defer errServer.Close()
+	data = ListPage{}
+	var statusErr *StatusError
+	err := f.Paginate(context.Background(), errServer.URL+"/list/1", &data, Pagination{})
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound || len(data.Items) != 4 {
+		t.Fatalf("want 404 after 2 pages, but got %v %+v", err, data.Items)
+	}
+
+	//canceled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := f.Paginate(ctx, server.URL+"/list/1", &ListPage{}, Pagination{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("want context canceled, but got %v", err)
+	}
+}
+
+func TestPaginateChan(t *testing.T) {
+	server := newListServer(3, 0)
+	defer server.Close()
+	f := newTestFetcher(t, DefaultConfig())
+
+	var urls []string
+	for page := range f.PaginateChan(context.Background(), server.URL+"/list/1", &ListPage{}, Pagination{}) {
+		if page.Err != nil {
+			t.Fatal(page.Err)
+		}
+		data := page.Value.(*ListPage)
+		if data.Page != page.Index+1 || len(data.Items) != 2 {
+			t.Fatalf("unexpected page %v: %+v", page.Index, data)
+		}
+		urls = append(urls, page.URL)
+	}
+	if len(urls) != 3 || urls[2] != server.URL+"/list/3" {
+		t.Fatalf("unexpected urls: %v", urls)
+	}
+
+	//stop reading by cancel
+	ctx, cancel := context.WithCancel(context.Background())
+	pages := f.PaginateChan(ctx, server.URL+"/list/1", &ListPage{}, Pagination{})
+	<-pages
+	cancel()
+	for range pages {
+	}
+
+	for page := range f.PaginateChan(context.Background(), server.URL+"/list/1", ListPage{}, Pagination{}) {
+		if page.Err == nil {
+			t.Fatal("non-pointer must return error")
+		}
+	}
+}
@@ -0,0 +1,300 @@
+// Package infer infers the pagser tags of fields from a sample page and example values,
+// and generates a Go struct with the tags.
+//
+//	fields, err := infer.ParseSpec(strings.NewReader(`title="Pagser"` + "\n" + `tags="go"` + "\n" + `tags="html"`))
+//	results, err := infer.Infer(doc.Selection, fields)
+//	source, err := infer.Generate(results, infer.DefaultConfig())
+//
+// The fields are found by the elements whose trimmed text or attribute value is the example value,
+// the selectors are the shortest ones of stable ids, classes and tags that match the elements,
+// and each inferred tag is checked to parse the example values from the sample.
+package infer
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/foolin/pagser"
+)
+
+// Config configuration of generated code
+type Config struct {
+	Package  string //package name, default is `main`
+	TypeName string //struct type name, default is `Page`
+	Comment  string //comment of struct type, default is empty
+}
+
+// DefaultConfig the default Config
+//	Config{
+//		Package:  "main",
+//		TypeName: "Page",
+//	}
+func DefaultConfig() Config {
+	return Config{
+		Package:  "main",
+		TypeName: "Page",
+	}
+}
+
+// Field the field name and example values
+type Field struct {
+	Name   string   //field name, eg: `title` or `image_url`
+	Values []string //example values in document order
+	List   bool     //field is []string, it is true if the field has multiple values
+}
+
+// Result the inferred field
+type Result struct {
+	Field  *Field
+	GoName string //exported Go field name, eg: `ImageURL`
+	Type   string //Go type, `string` or `[]string`
+	Tag    string //pagser tag, eg: `h1.title` or `ul.tags a->eachText()`
+}
+
+// ParseSpec parse the fields of spec, one `name=value` per line, the value can be a quoted Go string.
+// The values of repeated name are the values of a list field, and the name with `[]` suffix is a list field.
+// Empty lines and lines starting with `#` are ignored.
+//	title="Pagser"
+//	price=19.99
+//	tags[]="go"
+func ParseSpec(r io.Reader) ([]*Field, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return ParseSpecLines(lines)
+}
+
+// ParseSpecLines parse the fields of spec lines, see ParseSpec
+func ParseSpecLines(lines []string) ([]*Field, error) {
+	var fields []*Field
+	byName := make(map[string]*Field)
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		value = strings.TrimSpace(value)
+		list := strings.HasSuffix(name, "[]")
+		name = strings.TrimSuffix(name, "[]")
+		if !ok || goName(name) == "" {
+			return nil, fmt.Errorf("line %v: `%v` must be name=value", i+1, line)
+		}
+		if strings.HasPrefix(value, `"`) || strings.HasPrefix(value, "`") {
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %v: invalid quoted value %v: %v", i+1, value, err)
+			}
+			value = unquoted
+		}
+		if value == "" {
+			return nil, fmt.Errorf("line %v: value of %v must not empty", i+1, name)
+		}
+		field, ok := byName[name]
+		if !ok {
+			field = &Field{Name: name}
+			byName[name] = field
+			fields = append(fields, field)
+		}
+		field.Values = append(field.Values, value)
+		field.List = field.List || list || len(field.Values) > 1
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("no fields in spec")
+	}
+	return fields, nil
+}
+
+// Infer the tags of fields from selection, the results of inferred fields are returned with pagser.ParseErrors
+func Infer(selection *goquery.Selection, fields []*Field) ([]*Result, error) {
+	p := pagser.New()
+	results := make([]*Result, 0, len(fields))
+	names := make(map[string]string)
+	var errs pagser.ParseErrors
+	for _, field := range fields {
+		result, err := InferField(p, selection, field)
+		if err == nil {
+			if other, ok := names[result.GoName]; ok {
+				err = fmt.Errorf("Go name %v is same as field `%v`", result.GoName, other)
+			}
+		}
+		if err != nil {
+			errs = append(errs, &pagser.FieldError{Path: field.Name, Err: err})
+			continue
+		}
+		names[result.GoName] = field.Name
+		results = append(results, result)
+	}
+	if len(errs) > 0 {
+		return results, errs
+	}
+	return results, nil
+}
+
+// InferField infer the tag of field, the tag is checked to parse the example values by p
+func InferField(p *pagser.Pagser, selection *goquery.Selection, field *Field) (*Result, error) {
+	if len(field.Values) == 0 {
+		return nil, errors.New("no example values")
+	}
+	name := goName(field.Name)
+	if name == "" {
+		return nil, fmt.Errorf("invalid field name %v", field.Name)
+	}
+	result := &Result{Field: field, GoName: name, Type: "string"}
+	if field.List {
+		result.Type = "[]string"
+	}
+	//the selector of single value must match the element only, and the selector of list matches the fewest elements
+	size := 0
+	for _, match := range findMatches(selection, field.Values[0]) {
+		for _, selector := range pagser.SelectorCandidates(selection, match.node) {
+			n := selection.Find(selector).Length()
+			if (!field.List && n != 1) || (result.Tag != "" && n >= size) {
+				continue
+			}
+			tag := buildTag(selector, match.attr, field.List)
+			if checkTag(p, selection, tag, field) {
+				result.Tag, size = tag, n
+				if !field.List {
+					return result, nil
+				}
+			}
+		}
+	}
+	if result.Tag == "" {
+		return nil, fmt.Errorf("no element matches %q", field.Values[0])
+	}
+	return result, nil
+}
+
+// Generate the Go source of struct with the fields of results
+func Generate(results []*Result, cfg Config) ([]byte, error) {
+	if cfg.Package == "" || cfg.TypeName == "" {
+		return nil, errors.New("Package and TypeName must not empty")
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "package %v\n\n", cfg.Package)
+	if cfg.Comment != "" {
+		for _, line := range strings.Split(cfg.Comment, "\n") {
+			fmt.Fprintf(&buf, "// %v\n", line)
+		}
+	}
+	fmt.Fprintf(&buf, "type %v struct {\n", cfg.TypeName)
+	for _, result := range results {
+		fmt.Fprintf(&buf, "\t%v %v `pagser:%v`\n", result.GoName, result.Type, strconv.Quote(result.Tag))
+	}
+	buf.WriteString("}\n")
+	return format.Source(buf.Bytes())
+}
+
+// match the element of example value, attr is the attribute name, or empty if it is the text
+type match struct {
+	node *goquery.Selection
+	attr string
+}
+
+// findMatches returns the deepest elements whose trimmed text is value, then the elements with attribute value
+func findMatches(selection *goquery.Selection, value string) []*match {
+	var texts []*goquery.Selection
+	var attrs []*match
+	selection.Find("*").Each(func(i int, node *goquery.Selection) {
+		if pagser.IsIgnoredTag(goquery.NodeName(node)) {
+			return
+		}
+		for _, attr := range node.Get(0).Attr {
+			if attr.Key != "class" && attr.Key != "style" && attr.Val == value {
+				attrs = append(attrs, &match{node: node, attr: attr.Key})
+			}
+		}
+		if strings.TrimSpace(node.Text()) == value {
+			texts = append(texts, node)
+		}
+	})
+	matches := make([]*match, 0, len(texts)+len(attrs))
+	for _, node := range texts {
+		deepest := true
+		node.Children().EachWithBreak(func(i int, child *goquery.Selection) bool {
+			deepest = strings.TrimSpace(child.Text()) != value
+			return deepest
+		})
+		if deepest {
+			matches = append(matches, &match{node: node})
+		}
+	}
+	return append(matches, attrs...)
+}
+
+// buildTag returns the tag of selector and function
+func buildTag(selector string, attr string, list bool) string {
+	switch {
+	case list && attr != "":
+		return fmt.Sprintf("%v->eachAttr(%v)", selector, attr)
+	case list:
+		return selector + "->eachText()"
+	case attr != "":
+		return fmt.Sprintf("%v->attr(%v)", selector, attr)
+	}
+	return selector
+}
+
+// checkTag returns true if the tag parses the example values of field, the value of list field
+// must contain the example values in order.
+func checkTag(p *pagser.Pagser, selection *goquery.Selection, tag string, field *Field) bool {
+	out, err := p.ParseSelectionWithRules(selection, pagser.Rules{"v": {Selector: tag}})
+	if err != nil {
+		return false
+	}
+	if !field.List {
+		return out["v"] == field.Values[0]
+	}
+	list, ok := out["v"].([]string)
+	if !ok {
+		return false
+	}
+	i := 0
+	for _, v := range list {
+		if i < len(field.Values) && v == field.Values[i] {
+			i++
+		}
+	}
+	return i == len(field.Values)
+}
+
+// initialisms the words are upper case in Go names
+var initialisms = map[string]bool{"id": true, "url": true, "html": true, "api": true, "http": true, "json": true, "css": true, "ip": true}
+
+// goName returns the exported Go name of field name, eg: `image_url` is `ImageURL`
+func goName(name string) string {
+	words := strings.FieldsFunc(name, func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsDigit(c)
+	})
+	var b strings.Builder
+	for _, word := range words {
+		if initialisms[strings.ToLower(word)] {
+			b.WriteString(strings.ToUpper(word))
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	result := b.String()
+	if result == "" || !unicode.IsLetter([]rune(result)[0]) {
+		return ""
+	}
+	return result
+}
@@ -0,0 +1,113 @@
+package infer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/foolin/pagser"
+)
+
+const rawSampleHtml = `<html><head><title>Pagser - Shop</title></head>
+<body>
+<nav><ul><li><a href="/">Home</a></li><li><a href="/docs">Docs</a></li></ul></nav>
+<div id="product" class="css-1x2y3z">
+	<h1 class="name"> Pagser </h1>
+	<span class="price sale">19.99</span>
+	<span class="price">29.99</span>
+	<img src="/img/pagser.png" alt="logo">
+	<ul class="tags"><li>go</li><li>html</li><li>parser</li></ul>
+	<p><a href="/buy">Buy</a></p>
+</div>
+</body></html>`
+
+// InferPage the struct generated from rawSampleHtml by TestGenerate
+type InferPage struct {
+	Name     string   `pagser:"h1.name"`
+	Price    string   `pagser:"span.price.sale"`
+	ImageURL string   `pagser:"img->attr(src)"`
+	Tags     []string `pagser:"ul.tags li->eachText()"`
+	Buy      string   `pagser:"p a->attr(href)"`
+}
+
+func TestParseSpec(t *testing.T) {
+	fields, err := ParseSpec(strings.NewReader("# sample\nname=\"Pagser\"\n\nprice = 19.99\ntags=go\ntags=`html`\nlinks[]=/docs\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != 4 || fields[0].Values[0] != "Pagser" || fields[1].Values[0] != "19.99" || fields[1].List {
+		t.Fatalf("unexpected fields: %#v", fields)
+	}
+	if !fields[2].List || strings.Join(fields[2].Values, ",") != "go,html" || !fields[3].List {
+		t.Fatalf("unexpected list fields: %#v %#v", fields[2], fields[3])
+	}
+	for _, spec := range []string{"", "name", "=x", "name=", `name="x`} {
+		if _, err := ParseSpec(strings.NewReader(spec)); err == nil {
+			t.Errorf("spec %q want error", spec)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(rawSampleHtml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields, err := ParseSpecLines([]string{`name="Pagser"`, `price="19.99"`, `image_url=/img/pagser.png`, `tags=go`, `tags=parser`, `buy=/buy`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := Infer(doc.Selection, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source, err := Generate(results, Config{Package: "infer", TypeName: "InferPage", Comment: "InferPage the struct generated from rawSampleHtml by TestGenerate"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "package infer\n\n" +
+		"// InferPage the struct generated from rawSampleHtml by TestGenerate\n" +
+		"type InferPage struct {\n" +
+		"\tName     string   `pagser:\"h1.name\"`\n" +
+		"\tPrice    string   `pagser:\"span.price.sale\"`\n" +
+		"\tImageURL string   `pagser:\"img->attr(src)\"`\n" +
+		"\tTags     []string `pagser:\"ul.tags li->eachText()\"`\n" +
+		"\tBuy      string   `pagser:\"p a->attr(href)\"`\n" +
+		"}\n"
+	if string(source) != want {
+		t.Fatalf("want %v, but got %v", want, string(source))
+	}
+
+	//round trip on the sample
+	var page InferPage
+	if err = pagser.New().Parse(&page, rawSampleHtml); err != nil {
+		t.Fatal(err)
+	}
+	if page.Name != "Pagser" || page.Price != "19.99" || page.ImageURL != "/img/pagser.png" || len(page.Tags) != 3 || page.Buy != "/buy" {
+		t.Fatalf("unexpected page: %#v", page)
+	}
+}
+
+func TestInferErrors(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(rawSampleHtml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := []*Field{
+		{Name: "name", Values: []string{"Pagser"}},
+		{Name: "missing", Values: []string{"nope"}},
+		{Name: "Name", Values: []string{"19.99"}},
+	}
+	results, err := Infer(doc.Selection, fields)
+	var errs pagser.ParseErrors
+	if !errors.As(err, &errs) || len(errs) != 2 || errs[0].Path != "missing" || errs[1].Path != "Name" {
+		t.Fatalf("want errors of missing and Name, but got %v", err)
+	}
+	if len(results) != 1 || results[0].Tag != "h1.name" {
+		t.Fatalf("unexpected results: %#v", results)
+	}
+	if _, err = Generate(results, Config{}); err == nil {
+		t.Fatal("empty config want error")
+	}
+}
@@ -0,0 +1,45 @@
+// Package link resolves and compares the page links of fetch and crawl.
+package link
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+)
+
+// Resolve resolve the relative link against the page url, the link is returned if it is empty or invalid
+func Resolve(base *url.URL, link string) string {
+	if link == "" || base == nil {
+		return link
+	}
+	linkURL, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+	return base.ResolveReference(linkURL).String()
+}
+
+// Key the url without fragment to check the visited pages
+func Key(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	u.Fragment = ""
+	return u.String()
+}
+
+// String returns the string of link field value, it can be string, url.URL or fmt.Stringer,
+// it is empty if value is invalid or nil pointer
+func String(value reflect.Value) string {
+	if !value.IsValid() || !value.CanInterface() || (value.Kind() == reflect.Ptr && value.IsNil()) {
+		return ""
+	}
+	if value.CanAddr() {
+		value = value.Addr()
+	}
+	if stringer, ok := value.Interface().(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	return fmt.Sprint(reflect.Indirect(value).Interface())
+}
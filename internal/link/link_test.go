@@ -0,0 +1,57 @@
+package link
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	base, _ := url.Parse("https://example.com/list/1")
+	tests := []struct {
+		base *url.URL
+		link string
+		want string
+	}{
+		{base, "/item/1", "https://example.com/item/1"},
+		{base, "2", "https://example.com/list/2"},
+		{base, "", ""},
+		{nil, "/item/1", "/item/1"},
+		{base, ":bad", ":bad"},
+	}
+	for _, tt := range tests {
+		if got := Resolve(tt.base, tt.link); got != tt.want {
+			t.Errorf("Resolve(%v, %v) want %v, but got %v", tt.base, tt.link, tt.want, got)
+		}
+	}
+	if got := Key("https://example.com/item/1#top"); got != "https://example.com/item/1" {
+		t.Errorf("Key want url without fragment, but got %v", got)
+	}
+}
+
+func TestString(t *testing.T) {
+	u, _ := url.Parse("https://example.com/")
+	var nilURL *url.URL
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{"/a", "/a"},
+		{u, "https://example.com/"},
+		{*u, "https://example.com/"},
+		{nilURL, ""},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		value := reflect.ValueOf(tt.value)
+		if tt.value != nil && value.Kind() == reflect.Struct {
+			//addressable like struct fields
+			ptr := reflect.New(value.Type())
+			ptr.Elem().Set(value)
+			value = ptr.Elem()
+		}
+		if got := String(value); got != tt.want {
+			t.Errorf("String(%#v) want %v, but got %v", tt.value, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package pagser
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/spf13/cast"
+)
+
+// optionsTagSuffix field options tag name suffix, eg: `pagser_opts:"required,min=1"`
+const optionsTagSuffix = "_opts"
+
+// tagOptions field options of `pagser_opts` tag, options are separated by comma,
+// and the option value can be quoted by single quotes if it contains comma:
+//	Price string `pagser:".price" pagser_opts:"required,regex='^[0-9,.]+$'"`
+//
+// Validation options:
+//	required     selector matches at least one node, and the value is not empty,
+//	             struct, slice, pointer, map and Unmarshaler fields only need the matched nodes
+//	min=n        selector matches at least n nodes
+//	max=n        selector matches at most n nodes
+//	regex=expr   each value matches the regular expression
+//	oneof=a|b|c  each value is one of the `|` separated values
+//
+// Default option:
+//	default=val  set the field to `val` if selector matches nothing or the result is empty,
+//	             slice values are separated by comma, struct and map values are json.
+//
+// Slice option:
+//	parallel     parse the slice items by GOMAXPROCS goroutines, it overrides Config.Parallelism,
+//	             it is invalid for non-slice fields
+//	parallel=n   parse the slice items by n goroutines, `parallel=1` is sequential
+type tagOptions struct {
+	Value    string         `json:"-"` //raw options tag value
+	Required bool           `json:",omitempty"`
+	Min      int            `json:",omitempty"` //-1 if not set
+	Max      int            `json:",omitempty"` //-1 if not set
+	Regex    *regexp.Regexp `json:"-"`
+	OneOf    []string       `json:",omitempty"`
+	Default  *string        `json:",omitempty"` //nil if not set
+	Parallel int            `json:",omitempty"` //0 if not set
+}
+
+func newTagOptions(optsValue string) (*tagOptions, error) {
+	opts := &tagOptions{Value: optsValue, Min: -1, Max: -1}
+	for _, option := range splitOptions(optsValue) {
+		name, value, hasValue := strings.Cut(option, "=")
+		name = strings.TrimSpace(name)
+		value = unquoteOption(strings.TrimSpace(value))
+		var err error
+		switch name {
+		case "":
+			continue
+		case "required":
+			opts.Required = true
+		case "min":
+			opts.Min, err = strconv.Atoi(value)
+		case "max":
+			opts.Max, err = strconv.Atoi(value)
+		case "regex":
+			opts.Regex, err = regexp.Compile(value)
+		case "oneof":
+			opts.OneOf = strings.Split(value, "|")
+		case "default":
+			opts.Default = &value
+		case "parallel":
+			opts.Parallel = runtime.GOMAXPROCS(0)
+			if hasValue {
+				opts.Parallel, err = strconv.Atoi(value)
+				if err == nil && opts.Parallel < 1 {
+					err = fmt.Errorf("must be greater than 0")
+				}
+			}
+		default:
+			return nil, fmt.Errorf("options `%v` is invalid: unknown option `%v`", optsValue, name)
+		}
+		if err == nil && name != "required" && name != "parallel" && !hasValue {
+			err = fmt.Errorf("must has value")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("options `%v` is invalid: option `%v` %v", optsValue, name, err)
+		}
+	}
+	return opts, nil
+}
+
+// splitOptions split options by comma outside of single quotes
+func splitOptions(optsValue string) []string {
+	options := make([]string, 0)
+	inQuote := false
+	start := 0
+	for pos := 0; pos < len(optsValue); pos++ {
+		switch optsValue[pos] {
+		case '\\':
+			if inQuote {
+				//skip escape character
+				pos++
+			}
+		case '\'':
+			inQuote = !inQuote
+		case ',':
+			if !inQuote {
+				options = append(options, optsValue[start:pos])
+				start = pos + 1
+			}
+		}
+	}
+	return append(options, optsValue[start:])
+}
+
+// unquoteOption remove the single quotes of option value, and unescape `\'`
+func unquoteOption(value string) string {
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		return strings.ReplaceAll(value[1:len(value)-1], `\'`, `'`)
+	}
+	return value
+}
+
+// getOptions get tagOptions from cache, or create and cache it
+func (p *Pagser) getOptions(optsValue string) (*tagOptions, error) {
+	if cacheOpts, ok := p.mapOpts.Load(optsValue); ok && cacheOpts != nil {
+		return cacheOpts.(*tagOptions), nil
+	}
+	opts, err := newTagOptions(optsValue)
+	if err != nil {
+		return nil, err
+	}
+	p.mapOpts.Store(optsValue, opts)
+	return opts, nil
+}
+
+// needDefault the default value is set, and the tag output is empty,
+// the matched nodes of struct, pointer, slice, map and Unmarshaler fields are not empty without text
+func (opts *tagOptions) needDefault(out interface{}, matched int, nodes bool) bool {
+	if opts.Default == nil {
+		return false
+	}
+	if _, ok := out.(*goquery.Selection); ok && nodes {
+		return matched == 0
+	}
+	return matched == 0 || isEmptyValue(out)
+}
+
+// setDefaultValue set the default option value to field, struct and map values are decoded from json,
+// slice values are split by comma unless it is a json array.
+func (p *Pagser) setDefaultValue(fieldValue reflect.Value, value string) error {
+	fieldType := fieldValue.Type()
+	kind := fieldType.Kind()
+	if !p.isValueType(fieldType) {
+		elemKind := kind
+		if kind == reflect.Slice || kind == reflect.Array || kind == reflect.Ptr {
+			elemKind = fieldType.Elem().Kind()
+		}
+		isJson := strings.HasPrefix(strings.TrimSpace(value), "[") && kind == reflect.Slice
+		switch elemKind {
+		case reflect.Struct, reflect.Map, reflect.Slice, reflect.Ptr:
+			isJson = true
+		}
+		if isJson {
+			if err := json.Unmarshal([]byte(value), fieldValue.Addr().Interface()); err != nil {
+				return fmt.Errorf("default value `%v` is not json of %v: %v", value, fieldType, err)
+			}
+			return nil
+		}
+	}
+	if kind == reflect.Slice {
+		list := strings.Split(value, ",")
+		for i, item := range list {
+			list[i] = strings.TrimSpace(item)
+		}
+		return p.setRefectValue(kind, fieldValue, list)
+	}
+	return p.setRefectValue(kind, fieldValue, value)
+}
+
+// validate check the tag output by validation options, matched is the number of nodes matched by selector,
+// nodes is true if the field is parsed from the matched nodes, then `required` does not need the text of nodes
+func (opts *tagOptions) validate(out interface{}, matched int, nodes bool) error {
+	if opts.Min >= 0 && matched < opts.Min {
+		return &ValidationError{Rule: "min", Param: strconv.Itoa(opts.Min), Matched: matched}
+	}
+	if opts.Max >= 0 && matched > opts.Max {
+		return &ValidationError{Rule: "max", Param: strconv.Itoa(opts.Max), Matched: matched}
+	}
+	if !opts.Required && opts.Regex == nil && len(opts.OneOf) == 0 {
+		return nil
+	}
+	values := validationValues(out)
+	if opts.Required && matched == 0 {
+		return &ValidationError{Rule: "required", Matched: matched}
+	}
+	if _, ok := out.(*goquery.Selection); opts.Required && len(values) == 0 && !(ok && nodes) {
+		return &ValidationError{Rule: "required", Matched: matched}
+	}
+	for _, value := range values {
+		if opts.Regex != nil && !opts.Regex.MatchString(value) {
+			return &ValidationError{Rule: "regex", Param: opts.Regex.String(), Value: value, Matched: matched}
+		}
+		if len(opts.OneOf) > 0 && !containsString(opts.OneOf, value) {
+			return &ValidationError{Rule: "oneof", Param: strings.Join(opts.OneOf, "|"), Value: value, Matched: matched}
+		}
+	}
+	return nil
+}
+
+// validationValues returns the non-empty string values of tag output
+func validationValues(out interface{}) []string {
+	var list []string
+	switch v := out.(type) {
+	case *goquery.Selection:
+		list = v.Map(func(i int, node *goquery.Selection) string {
+			return node.Text()
+		})
+	case []string:
+		list = v
+	default:
+		if isEmptyValue(out) {
+			return nil
+		}
+		if items, err := cast.ToStringSliceE(out); err == nil && !isScalar(out) {
+			list = items
+		} else {
+			list = []string{cast.ToString(out)}
+		}
+	}
+	values := make([]string, 0, len(list))
+	for _, value := range list {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// isScalar the value is not slice or array
+func isScalar(v interface{}) bool {
+	switch v.(type) {
+	case string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return true
+	}
+	return false
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,221 @@
+package pagser
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const rawOptionsHtml = `
+<html>
+<body>
+	<h1>Product</h1>
+	<span class="sku">12345</span>
+	<span class="stock">in_stock</span>
+	<ul>
+		<li class="tag">a</li>
+		<li class="tag">b</li>
+		<li class="tag">c</li>
+	</ul>
+</body>
+</html>
+`
+
+type OptionsValidData struct {
+	Title string   `pagser:"h1" pagser_opts:"required"`
+	Sku   int      `pagser:".sku" pagser_opts:"required,regex='^[0-9]{1,6}$'"`
+	Stock string   `pagser:".stock" pagser_opts:"oneof=in_stock|out_of_stock"`
+	Tags  []string `pagser:".tag->eachText()" pagser_opts:"min=1,max=3,regex=^[a-z]$"`
+	Note  string   `pagser:".note" pagser_opts:"regex=^[0-9]+$"`
+}
+
+type OptionsInvalidData struct {
+	Title  string   `pagser:"h1" pagser_opts:"required"`
+	Price  string   `pagser:".price" pagser_opts:"required"`
+	Sku    string   `pagser:".sku" pagser_opts:"regex=^[a-z]+$"`
+	Stock  string   `pagser:".stock" pagser_opts:"oneof=yes|no"`
+	Tags   []string `pagser:".tag->eachText()" pagser_opts:"max=2"`
+	Images []string `pagser:"img->eachAttr(src)" pagser_opts:"min=1"`
+}
+
+func TestParseValidation(t *testing.T) {
+	p := New()
+
+	var valid OptionsValidData
+	if err := p.Parse(&valid, rawOptionsHtml); err != nil {
+		t.Fatal(err)
+	}
+	if valid.Sku != 12345 || len(valid.Tags) != 3 {
+		t.Errorf("unexpected data: %v", prettyJson(valid))
+	}
+
+	var invalid OptionsInvalidData
+	err := p.Parse(&invalid, rawOptionsHtml)
+	var validErr *ValidationError
+	if !errors.As(err, &validErr) || validErr.Rule != "required" || validErr.Matched != 0 {
+		t.Fatalf("error want required ValidationError, but got %v", err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.ContinueOnError = true
+	p, err = NewWithConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = p.Parse(&invalid, rawOptionsHtml)
+	var parseErrs ParseErrors
+	if !errors.As(err, &parseErrs) {
+		t.Fatalf("error want ParseErrors, but got %v", err)
+	}
+	tests := []struct {
+		path  string
+		rule  string
+		value string
+	}{
+		{"Price", "required", ""},
+		{"Sku", "regex", "12345"},
+		{"Stock", "oneof", "in_stock"},
+		{"Tags", "max", ""},
+		{"Images", "min", ""},
+	}
+	if len(parseErrs) != len(tests) {
+		t.Fatalf("want %v errors, but got %v", len(tests), err)
+	}
+	for i, tt := range tests {
+		if parseErrs[i].Path != tt.path || !errors.As(parseErrs[i], &validErr) ||
+			validErr.Rule != tt.rule || validErr.Value != tt.value {
+			t.Errorf("error %v want %v %v `%v`, but got %v", i, tt.path, tt.rule, tt.value, parseErrs[i])
+		}
+	}
+	if invalid.Title != "Product" {
+		t.Errorf("valid fields want parsed, but got %v", prettyJson(invalid))
+	}
+}
+
+func TestNewTagOptions(t *testing.T) {
+	opts, err := newTagOptions(`required, min=2, regex='^[0-9,]+$', oneof=a|b`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opts.Required || opts.Min != 2 || opts.Max != -1 || opts.Regex.String() != "^[0-9,]+$" || len(opts.OneOf) != 2 {
+		t.Errorf("unexpected options: %#v", opts)
+	}
+	for _, optsValue := range []string{"unknown", "min=a", "max", "regex=[a-"} {
+		if _, err := newTagOptions(optsValue); err == nil {
+			t.Errorf("options `%v` want error", optsValue)
+		}
+	}
+}
+
+type OptionsDefaultItem struct {
+	Name string `pagser:".name"`
+}
+
+type OptionsDefaultData struct {
+	Title   string               `pagser:"h1" pagser_opts:"default=Untitled"`
+	Price   float64              `pagser:".price" pagser_opts:"default=9.9"`
+	Count   int                  `pagser:".count->text()" pagser_opts:"default=-1"`
+	InStock bool                 `pagser:".in-stock" pagser_opts:"default=true"`
+	Tags    []string             `pagser:".tags li->eachText()" pagser_opts:"default='x, y'"`
+	Ids     []int                `pagser:".ids li" pagser_opts:"default='1,2,3'"`
+	Created time.Time            `pagser:".created" pagser_opts:"default=2020-01-02"`
+	Item    OptionsDefaultItem   `pagser:".item" pagser_opts:"default='{\"Name\":\"none\"}'"`
+	Items   []OptionsDefaultItem `pagser:".items li" pagser_opts:"default='[{\"Name\":\"a\"},{\"Name\":\"b\"}]'"`
+	Specs   map[string]string    `pagser:".specs tr" pagser_opts:"default='{\"color\":\"red\"}'"`
+	Sku     string               `pagser:".sku" pagser_opts:"default=0"`
+	Note    string               `pagser:".missing" pagser_opts:"required,default=none"`
+}
+
+func TestParseDefault(t *testing.T) {
+	var data OptionsDefaultData
+	err := New().Parse(&data, rawOptionsHtml)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := OptionsDefaultData{
+		Title:   "Product",
+		Price:   9.9,
+		Count:   -1,
+		InStock: true,
+		Tags:    []string{"x", "y"},
+		Ids:     []int{1, 2, 3},
+		Created: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+		Item:    OptionsDefaultItem{Name: "none"},
+		Items:   []OptionsDefaultItem{{Name: "a"}, {Name: "b"}},
+		Specs:   map[string]string{"color": "red"},
+		Sku:     "12345",
+		Note:    "none",
+	}
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("want %v, but got %v", prettyJson(want), prettyJson(data))
+	}
+
+	var invalid struct {
+		Item OptionsDefaultItem `pagser:".item" pagser_opts:"default=none"`
+	}
+	if err = New().Parse(&invalid, rawOptionsHtml); err == nil {
+		t.Error("invalid json default value want error")
+	}
+}
+
+const rawOptionsNodesHtml = `
+<html>
+<body>
+	<img class="logo" src="/logo.png">
+	<div class="box"><img src="/box.png"></div>
+	<ul class="photos">
+		<li><img src="/1.png"></li>
+		<li><img src="/2.png"></li>
+	</ul>
+</body>
+</html>
+`
+
+type OptionsImage struct {
+	Src string `pagser:"->attr(src)"`
+}
+
+type OptionsBox struct {
+	Img string `pagser:"img->attr(src)"`
+}
+
+func TestParseRequiredNodes(t *testing.T) {
+	var data struct {
+		Imgs []OptionsImage `pagser:"img" pagser_opts:"required"`
+		Box  OptionsBox     `pagser:"div.box" pagser_opts:"required"`
+		Logo *OptionsImage  `pagser:"img.logo" pagser_opts:"required"`
+	}
+	if err := New().Parse(&data, rawOptionsNodesHtml); err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Imgs) != 4 || data.Box.Img != "/box.png" || data.Logo == nil || data.Logo.Src != "/logo.png" {
+		t.Errorf("unexpected data: %v", prettyJson(data))
+	}
+
+	var text struct {
+		Box string `pagser:"div.box" pagser_opts:"required"`
+	}
+	err := New().Parse(&text, rawOptionsNodesHtml)
+	var validErr *ValidationError
+	if !errors.As(err, &validErr) || validErr.Rule != "required" || validErr.Matched != 1 {
+		t.Fatalf("text field without text want required ValidationError, but got %v", err)
+	}
+}
+
+func TestParseDefaultNodes(t *testing.T) {
+	var data struct {
+		Logo    OptionsImage `pagser:"img.logo" pagser_opts:"default='{\"Src\":\"def\"}'"`
+		Box     *OptionsBox  `pagser:"div.box" pagser_opts:"default='{\"Img\":\"def\"}'"`
+		Photos  []OptionsBox `pagser:".photos li" pagser_opts:"default='[{\"Img\":\"def\"}]'"`
+		Missing OptionsImage `pagser:"img.missing" pagser_opts:"default='{\"Src\":\"def\"}'"`
+		Text    string       `pagser:"div.box" pagser_opts:"default=def"`
+	}
+	if err := New().Parse(&data, rawOptionsNodesHtml); err != nil {
+		t.Fatal(err)
+	}
+	if data.Logo.Src != "/logo.png" || data.Box == nil || data.Box.Img != "/box.png" ||
+		len(data.Photos) != 2 || data.Photos[1].Img != "/2.png" || data.Missing.Src != "def" || data.Text != "" {
+		t.Errorf("unexpected data: %v", prettyJson(data))
+	}
+}
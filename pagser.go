@@ -11,15 +11,26 @@ package pagser
 import (
 	"errors"
 	"sync"
+	"sync/atomic"
 )
 
 // Pagser the page parser
 type Pagser struct {
 	Config Config
-	//mapTags  map[string]*tagTokenizer // tag value => tagTokenizer
-	mapTags sync.Map //map[string]*tagTokenizer
+	//mapTags  map[tagKey]*tagTokenizer // tag value and function symbol => tagTokenizer
+	mapTags sync.Map //map[tagKey]*tagTokenizer
 	//mapFuncs map[string]CallFunc      // name => func
 	mapFuncs sync.Map //map[string]CallFunc
+	//mapValueFuncs map[string]ValueFunc // name => func
+	mapValueFuncs sync.Map //map[string]ValueFunc
+	//mapOpts map[string]*tagOptions // options tag value => tagOptions
+	mapOpts sync.Map //map[string]*tagOptions
+	//plans map[planKey]*structPlan // struct type, tag name and function symbol => structPlan
+	plans sync.Map //map[planKey]*structPlan
+	//noPlanCache compile the plan of struct for each parse and find methods by name, it is the baseline of plan cache benchmarks
+	noPlanCache bool
+	//builtinReplaced a builtin function is replaced by registered function, the generated Parser is not used
+	builtinReplaced atomic.Bool
 }
 
 // New create pagser client
@@ -44,5 +55,27 @@ func NewWithConfig(cfg Config) (*Pagser, error) {
 	for k, v := range builtinFuncs {
 		p.mapFuncs.Store(k, v)
 	}
+	for k, v := range builtinContextFuncs {
+		p.mapFuncs.Store(k, v)
+	}
+	for k, v := range builtinValueFuncs {
+		p.mapValueFuncs.Store(k, v)
+	}
 	return &p, nil
 }
+
+// Clone returns a copy of pagser with the Config and registered functions, the cached tags and plans are not copied.
+// The caches grow with each struct type and tag, clone it to parse the structs and rules of user input.
+func (p *Pagser) Clone() *Pagser {
+	c := &Pagser{Config: p.Config}
+	p.mapFuncs.Range(func(key, value interface{}) bool {
+		c.mapFuncs.Store(key, value)
+		return true
+	})
+	p.mapValueFuncs.Range(func(key, value interface{}) bool {
+		c.mapValueFuncs.Store(key, value)
+		return true
+	})
+	c.builtinReplaced.Store(p.builtinReplaced.Load())
+	return c
+}
@@ -3,6 +3,8 @@ package pagser
 import (
 	"fmt"
 	"testing"
+
+	"github.com/PuerkitoBio/goquery"
 )
 
 const rawPagserHtml = `
@@ -109,3 +111,29 @@ func TestNewWithConfigFuncSymbolError(t *testing.T) {
 		t.Fatal("Result must return error")
 	}
 }
+
+func TestClone(t *testing.T) {
+	p := New()
+	p.Config.CastError = true
+	p.RegisterFunc("hello", func(node *goquery.Selection, args ...string) (out interface{}, err error) {
+		return "hello " + node.Text(), nil
+	})
+	var data struct {
+		Title string `pagser:"title->hello()"`
+	}
+	if err := p.Parse(&data, rawPagserHtml); err != nil {
+		t.Fatal(err)
+	}
+	c := p.Clone()
+	if !c.Config.CastError {
+		t.Error("config must be copied")
+	}
+	c.mapTags.Range(func(key, value interface{}) bool {
+		t.Errorf("tag %v must not be copied", key)
+		return true
+	})
+	data.Title = ""
+	if err := c.Parse(&data, rawPagserHtml); err != nil || data.Title != "hello Pagser Example" {
+		t.Errorf("registered function must be copied, but got %v, error %v", data.Title, err)
+	}
+}
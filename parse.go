@@ -1,10 +1,18 @@
 package pagser
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"net/url"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/spf13/cast"
@@ -12,34 +20,170 @@ import (
 
 // Parse parse html to struct
 func (p *Pagser) Parse(v interface{}, document string) (err error) {
+	return p.ParseContext(context.Background(), v, document)
+}
+
+// ParseContext parse html to struct, the parsing stops with the context error if ctx is done
+func (p *Pagser) ParseContext(ctx context.Context, v interface{}, document string) (err error) {
 	reader, err := goquery.NewDocumentFromReader(strings.NewReader(document))
 	if err != nil {
 		return err
 	}
-	return p.ParseDocument(v, reader)
+	return p.ParseDocumentContext(ctx, v, reader)
+}
+
+// ParseWithBaseURL parse html to struct, absHref(), absSrc() and absAttr(name) resolve the links against baseURL
+func (p *Pagser) ParseWithBaseURL(v interface{}, document string, baseURL string) (err error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("invalid base url: %v error: %v", baseURL, err)
+	}
+	return p.ParseContext(WithBaseURL(context.Background(), u), v, document)
 }
 
-// ParseReader parse html to struct
+// ParseReader parse html to struct, the html is transcoded to UTF-8 by the charset of BOM or `<meta charset>`
 func (p *Pagser) ParseReader(v interface{}, reader io.Reader) (err error) {
-	doc, err := goquery.NewDocumentFromReader(reader)
+	return p.ParseReaderContext(context.Background(), v, reader)
+}
+
+// ParseReaderContext parse html to struct, the parsing stops with the context error if ctx is done
+func (p *Pagser) ParseReaderContext(ctx context.Context, v interface{}, reader io.Reader) (err error) {
+	return p.parseReader(ctx, v, reader, "")
+}
+
+// ParseReaderContentType parse html to struct, the charset of contentType hint is used if it is not empty,
+// eg: `text/html; charset=gbk`
+func (p *Pagser) ParseReaderContentType(v interface{}, reader io.Reader, contentType string) (err error) {
+	return p.parseReader(context.Background(), v, reader, contentType)
+}
+
+// ParseResponse parse the body of http response to struct, the charset of Content-Type header is used,
+// and the links of absHref(), absSrc() and absAttr(name) are resolved against the request url.
+// The body is not closed.
+func (p *Pagser) ParseResponse(v interface{}, resp *http.Response) (err error) {
+	return p.ParseResponseContext(context.Background(), v, resp)
+}
+
+// ParseResponseContext parse the body of http response to struct, the parsing stops with the context error if ctx is done
+func (p *Pagser) ParseResponseContext(ctx context.Context, v interface{}, resp *http.Response) (err error) {
+	if resp == nil || resp.Body == nil {
+		return errors.New("response body is nil")
+	}
+	if resp.Request != nil && resp.Request.URL != nil {
+		ctx = WithBaseURL(ctx, resp.Request.URL)
+	}
+	return p.parseReader(ctx, v, resp.Body, resp.Header.Get("Content-Type"))
+}
+
+func (p *Pagser) parseReader(ctx context.Context, v interface{}, reader io.Reader, contentType string) (err error) {
+	doc, err := NewDocument(reader, contentType)
 	if err != nil {
 		return err
 	}
-	return p.ParseDocument(v, doc)
+	return p.ParseDocumentContext(ctx, v, doc)
 }
 
 // ParseDocument parse document to struct
 func (p *Pagser) ParseDocument(v interface{}, document *goquery.Document) (err error) {
-	return p.ParseSelection(v, document.Selection)
+	return p.ParseDocumentContext(context.Background(), v, document)
+}
+
+// ParseDocumentContext parse document to struct, the parsing stops with the context error if ctx is done,
+// the url of document is the base url if the context has no base url.
+func (p *Pagser) ParseDocumentContext(ctx context.Context, v interface{}, document *goquery.Document) (err error) {
+	if document.Url != nil && BaseURL(ctx) == nil {
+		ctx = WithBaseURL(ctx, document.Url)
+	}
+	return p.ParseSelectionContext(ctx, v, document.Selection)
 }
 
-// ParseSelection parse selection to struct
+// ParseSelection parse selection to struct, the generated ParsePagser method is used if v implements Parser, see Parser
 func (p *Pagser) ParseSelection(v interface{}, selection *goquery.Selection) (err error) {
-	return p.doParse(v, nil, selection)
+	return p.ParseSelectionContext(context.Background(), v, selection)
+}
+
+// ParseSelectionContext parse selection to struct, the context is checked before each field and slice item,
+// and passed to the functions registered by RegisterFuncContext and the methods with context argument.
+// The parsing stops with the context error if ctx is done.
+func (p *Pagser) ParseSelectionContext(ctx context.Context, v interface{}, selection *goquery.Selection) (err error) {
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+	if parser, ok := v.(Parser); ok && p.canUseParser(ctx, parser) {
+		return parser.ParsePagser(selection)
+	}
+	state := p.newParseState(withDocumentBaseURL(ctx, selection))
+	err = p.doParse(state, v, nil, selection, "")
+	if err != nil {
+		return err
+	}
+	if len(state.errs) > 0 {
+		return state.errs
+	}
+	return nil
+}
+
+// canUseParser the generated ParsePagser method parses the same as reflection, it is not used if the context
+// can be done or has base url, Config.CastError or Config.ContinueOnError is set, a builtin function is replaced
+// by registered function, or the parser is generated from the other tag name or function symbol than Config.
+func (p *Pagser) canUseParser(ctx context.Context, parser Parser) bool {
+	tagName, funcSymbol := parser.PagserTags()
+	return tagName == p.Config.TagName && funcSymbol == p.Config.FuncSymbol &&
+		ctx.Done() == nil && BaseURL(ctx) == nil && !p.Config.CastError && !p.Config.ContinueOnError && !p.builtinReplaced.Load()
+}
+
+// parseState the state of one parse call
+type parseState struct {
+	ctx             context.Context
+	continueOnError bool
+	errs            ParseErrors
+	onSelect        func(tag *tagTokenizer, node *goquery.Selection) //called with the nodes matched by each tried alternative
+	onFunc          func(fn *tagFunc, out interface{}, err error)    //called with the output of each function
+	tracer          *tracer                                          //nil if not tracing
+}
+
+func (p *Pagser) newParseState(ctx context.Context) *parseState {
+	return &parseState{ctx: ctx, continueOnError: p.Config.ContinueOnError}
+}
+
+// fork returns the state of a slice item parsed by another goroutine, the item has its own errors and tracer,
+// the other hooks are shared and must be safe for concurrent use.
+func (state *parseState) fork() *parseState {
+	item := &parseState{
+		ctx:             state.ctx,
+		continueOnError: state.continueOnError,
+		onSelect:        state.onSelect,
+		onFunc:          state.onFunc,
+	}
+	if state.tracer != nil {
+		item.tracer = state.tracer.fork()
+		item.onSelect = item.tracer.selected
+		item.onFunc = item.tracer.called
+	}
+	return item
+}
+
+// merge the errors and traces of item state
+func (state *parseState) merge(item *parseState) {
+	state.errs = append(state.errs, item.errs...)
+	state.tracer.merge(item.tracer)
+}
+
+// fail returns the field error, or records it and returns nil if continue on error and the context is not done.
+func (state *parseState) fail(path string, tag *tagTokenizer, err error) error {
+	fieldErr, ok := err.(*FieldError)
+	if !ok {
+		fieldErr = newFieldError(path, tag, err)
+	}
+	if !state.continueOnError || state.ctx.Err() != nil {
+		return fieldErr
+	}
+	state.errs = append(state.errs, fieldErr)
+	return nil
 }
 
-// ParseSelection parse selection to struct
-func (p *Pagser) doParse(v interface{}, stackRefValues []reflect.Value, selection *goquery.Selection) (err error) {
+// doParse parse selection to struct, path is the field path of struct, eg: `Items[3]`
+func (p *Pagser) doParse(state *parseState, v interface{}, stackRefValues []reflect.Value, selection *goquery.Selection, path string) (err error) {
 	objRefType := reflect.TypeOf(v)
 	objRefValue := reflect.ValueOf(v)
 
@@ -52,204 +196,671 @@ func (p *Pagser) doParse(v interface{}, stackRefValues []reflect.Value, selectio
 		return fmt.Errorf("%v is nil", objRefType)
 	}
 
-	objRefTypeElem := objRefType.Elem()
 	objRefValueElem := objRefValue.Elem()
-
-	for i := 0; i < objRefValueElem.NumField(); i++ {
-		fieldType := objRefTypeElem.Field(i)
-		fieldValue := objRefValueElem.Field(i)
-		kind := fieldType.Type.Kind()
-
-		//tagValue := fieldType.Tag.Get(parserTagName)
-		tagValue, tagOk := fieldType.Tag.Lookup(p.Config.TagName)
-		if !tagOk {
-			if p.Config.Debug {
-				fmt.Printf("[INFO] not found tag name=[%v] in field: %v, eg: `%v:\".navlink a->attr(href)\"`\n",
-					p.Config.TagName, fieldType.Name, p.Config.TagName)
-			}
-			continue
+	plan := p.getPlan(objRefType.Elem())
+	for _, field := range plan.fields {
+		if err = state.ctx.Err(); err != nil {
+			return err
 		}
-		if tagValue == ignoreSymbol {
+		fieldValue := objRefValueElem.Field(field.index)
+		fieldPath := joinFieldPath(path, field.name)
+		fieldTrace := state.tracer.begin(fieldPath, field.tag)
+		if field.err != nil {
+			state.tracer.end(fieldTrace, fieldValue, field.err)
+			if err = state.fail(fieldPath, field.tag, field.err); err != nil {
+				return err
+			}
 			continue
 		}
 
-		cacheTag, ok := p.mapTags.Load(tagValue)
-		var tag *tagTokenizer
-		if !ok || cacheTag == nil {
-			tag, err = p.newTag(tagValue)
-			if err != nil {
+		if stackRefValues == nil {
+			stackRefValues = make([]reflect.Value, 0)
+		}
+		err = p.parseField(state, objRefValue, stackRefValues, field, fieldValue, selection, fieldPath)
+		state.tracer.end(fieldTrace, fieldValue, err)
+		if err != nil {
+			if err = state.fail(fieldPath, field.tag, err); err != nil {
 				return err
 			}
-			p.mapTags.Store(tagValue, tag)
-		} else {
-			tag = cacheTag.(*tagTokenizer)
-		}
-
-		node := selection
-		if tag.Selector != "" {
-			node = selection.Find(tag.Selector)
 		}
+	}
+	return nil
+}
 
-		var callOutValue interface{}
-		var callErr error
-		if tag.FuncName != "" {
-			callOutValue, callErr = p.findAndExecFunc(objRefValue, stackRefValues, tag, node)
-			if callErr != nil {
-				return fmt.Errorf("tag=`%v` parse func error: %v", tagValue, callErr)
-			}
-			if subNode, ok := callOutValue.(*goquery.Selection); ok {
-				//set sub node to current node
-				node = subNode
-			} else {
-				svErr := p.setRefectValue(fieldType.Type.Kind(), fieldValue, callOutValue)
-				if svErr != nil {
-					return fmt.Errorf("tag=`%v` set value error: %v", tagValue, svErr)
-				}
-				//goto parse next field
-				continue
+// parseField parse selection to the field by tag
+func (p *Pagser) parseField(state *parseState, objRefValue reflect.Value, stackRefValues []reflect.Value, field *fieldPlan, fieldValue reflect.Value, selection *goquery.Selection, path string) (err error) {
+	kind := field.typ.Kind()
+	tag := field.tag
+	callOutValue, matched, err := p.execTag(state, objRefValue, stackRefValues, tag, selection)
+	if err != nil {
+		return fmt.Errorf("parse func error: %w", err)
+	}
+	state.tracer.output(callOutValue, matched)
+	if opts := field.opts; opts != nil {
+		if opts.needDefault(callOutValue, matched, field.nodes) {
+			if err = p.setDefaultValue(fieldValue, *opts.Default); err != nil {
+				return fmt.Errorf("set default value error: %w", err)
 			}
+			return nil
+		}
+		if err = opts.validate(callOutValue, matched, field.nodes); err != nil {
+			return err
 		}
+	}
+	node, ok := callOutValue.(*goquery.Selection)
+	if !ok {
+		err = p.setRefectValue(kind, fieldValue, callOutValue)
+		if err != nil {
+			return fmt.Errorf("set value error: %w", err)
+		}
+		return nil
+	}
 
-		if stackRefValues == nil {
-			stackRefValues = make([]reflect.Value, 0)
+	stackRefValues = append(stackRefValues, objRefValue)
+
+	//set value
+	switch {
+	case isUnmarshaler(field.typ):
+		err = unmarshalSelection(fieldValue, node)
+		if err != nil {
+			return fmt.Errorf("unmarshal selection error: %w", err)
+		}
+	case p.isValueType(field.typ):
+		err = p.setRefectValue(kind, fieldValue, strings.TrimSpace(node.Text()))
+		if err != nil {
+			return fmt.Errorf("set value error: %w", err)
+		}
+	case kind == reflect.Ptr:
+		subModel := reflect.New(field.typ.Elem())
+		fieldValue.Set(subModel)
+		return p.doParse(state, subModel.Interface(), stackRefValues, node, path)
+		//Slice
+	case kind == reflect.Slice:
+		slice, err := p.parseSlice(state, stackRefValues, field, node, path)
+		if err != nil {
+			return err
+		}
+		fieldValue.Set(slice)
+	case kind == reflect.Struct:
+		subModel := reflect.New(field.typ)
+		err = p.doParse(state, subModel.Interface(), stackRefValues, node, path)
+		fieldValue.Set(subModel.Elem())
+		return err
+		//UnsafePointer
+		//Complex64
+		//Complex128
+		//Array
+		//Chan
+		//Func
+	case kind == reflect.Map:
+		return p.parseMap(state, objRefValue, stackRefValues, field, fieldValue, node, path)
+	default:
+		err = p.setRefectValue(kind, fieldValue, strings.TrimSpace(node.Text()))
+		if err != nil {
+			return fmt.Errorf("set value error: %w", err)
 		}
-		stackRefValues = append(stackRefValues, objRefValue)
+	}
+	return nil
+}
 
-		//set value
-		switch {
-		case kind == reflect.Ptr:
-			subModel := reflect.New(fieldType.Type.Elem())
-			fieldValue.Set(subModel)
-			err = p.doParse(subModel.Interface(), stackRefValues, node)
+// parseSlice parse each node to a slice item, the items are parsed by the goroutines of
+// `parallel` option or Config.Parallelism if it is greater than 1.
+func (p *Pagser) parseSlice(state *parseState, stackRefValues []reflect.Value, field *fieldPlan, node *goquery.Selection, path string) (reflect.Value, error) {
+	parallelism := p.Config.Parallelism
+	if field.opts != nil && field.opts.Parallel > 0 {
+		parallelism = field.opts.Parallel
+	}
+	if parallelism > 1 && node.Size() > 1 {
+		return p.parseSliceParallel(state, stackRefValues, field, node, path, parallelism)
+	}
+
+	var err error
+	slice := reflect.MakeSlice(field.typ, node.Size(), node.Size())
+	node.EachWithBreak(func(i int, subNode *goquery.Selection) bool {
+		if err = state.ctx.Err(); err != nil {
+			return false
+		}
+		itemPath := indexFieldPath(path, strconv.Itoa(i))
+		var itemValue reflect.Value
+		itemValue, err = p.parseSliceItem(state, stackRefValues, field.typ.Elem(), subNode, itemPath)
+		if err != nil {
+			err = state.fail(itemPath, field.tag, err)
 			if err != nil {
-				return fmt.Errorf("tag=`%v` %#v parser error: %v", tagValue, subModel, err)
+				return false
 			}
-			//Slice
-		case kind == reflect.Slice:
-			sliceType := fieldValue.Type()
-			itemType := sliceType.Elem()
-			itemKind := itemType.Kind()
-			slice := reflect.MakeSlice(sliceType, node.Size(), node.Size())
-			node.EachWithBreak(func(i int, subNode *goquery.Selection) bool {
-				//outhtml, _ := goquery.OuterHtml(subNode)
-				//log.Printf("%v => %v", i, outhtml)
-				itemValue := reflect.New(itemType).Elem()
-				switch {
-				case itemKind == reflect.Struct:
-					err = p.doParse(itemValue.Addr().Interface(), stackRefValues, subNode)
-					if err != nil {
-						err = fmt.Errorf("tag=`%v` %#v parser error: %v", tagValue, itemValue, err)
-						return false
-					}
-				case itemKind == reflect.Ptr && itemValue.Type().Elem().Kind() == reflect.Struct:
-					itemValue = reflect.New(itemType.Elem())
-					err = p.doParse(itemValue.Interface(), stackRefValues, subNode)
-					if err != nil {
-						err = fmt.Errorf("tag=`%v` %#v parser error: %v", tagValue, itemValue, err)
-						return false
+		}
+		slice.Index(i).Set(itemValue)
+		return true
+	})
+	return slice, err
+}
+
+// parseSliceParallel parse the slice items by goroutines, each item has its own parseState,
+// and the item errors and traces are merged in item order, so they are the same as sequential parsing.
+// No more items are started after an error if not continue on error.
+func (p *Pagser) parseSliceParallel(state *parseState, stackRefValues []reflect.Value, field *fieldPlan, node *goquery.Selection, path string, parallelism int) (reflect.Value, error) {
+	size := node.Size()
+	if parallelism > size {
+		parallelism = size
+	}
+	//the items must not append to the shared stack
+	stackRefValues = stackRefValues[:len(stackRefValues):len(stackRefValues)]
+
+	slice := reflect.MakeSlice(field.typ, size, size)
+	itemStates := make([]*parseState, size)
+	itemErrs := make([]error, size)
+	var stopped int32
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < parallelism; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				itemState := state.fork()
+				itemStates[i] = itemState
+				itemValue, err := p.parseSliceItem(itemState, stackRefValues, field.typ.Elem(), node.Eq(i), indexFieldPath(path, strconv.Itoa(i)))
+				if err != nil {
+					itemErrs[i] = err
+					if !state.continueOnError {
+						atomic.StoreInt32(&stopped, 1)
 					}
-				default:
-					itemValue.SetString(strings.TrimSpace(subNode.Text()))
 				}
 				slice.Index(i).Set(itemValue)
-				return true
+			}
+		}()
+	}
+	var err error
+	for i := 0; i < size; i++ {
+		if err = state.ctx.Err(); err != nil || atomic.LoadInt32(&stopped) == 1 {
+			break
+		}
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	for i, itemState := range itemStates {
+		if itemState == nil {
+			break
+		}
+		state.merge(itemState)
+		if itemErrs[i] != nil {
+			if failErr := state.fail(indexFieldPath(path, strconv.Itoa(i)), field.tag, itemErrs[i]); failErr != nil {
+				return slice, failErr
+			}
+		}
+	}
+	return slice, err
+}
+
+// parseSliceItem parse node to a slice item
+func (p *Pagser) parseSliceItem(state *parseState, stackRefValues []reflect.Value, itemType reflect.Type, subNode *goquery.Selection, itemPath string) (itemValue reflect.Value, err error) {
+	itemKind := itemType.Kind()
+	itemValue = reflect.New(itemType).Elem()
+	switch {
+	case isUnmarshaler(itemType):
+		err = unmarshalSelection(itemValue, subNode)
+		if err != nil {
+			err = fmt.Errorf("unmarshal selection error: %w", err)
+		}
+	case p.isValueType(itemType):
+		err = p.setRefectValue(itemKind, itemValue, strings.TrimSpace(subNode.Text()))
+		if err != nil {
+			err = fmt.Errorf("set value error: %w", err)
+		}
+	case itemKind == reflect.Struct:
+		err = p.doParse(state, itemValue.Addr().Interface(), stackRefValues, subNode, itemPath)
+	case itemKind == reflect.Ptr && itemType.Elem().Kind() == reflect.Struct:
+		itemValue = reflect.New(itemType.Elem())
+		err = p.doParse(state, itemValue.Interface(), stackRefValues, subNode, itemPath)
+	default:
+		err = p.setRefectValue(itemKind, itemValue, strings.TrimSpace(subNode.Text()))
+		if err != nil {
+			err = fmt.Errorf("set value error: %w", err)
+		}
+	}
+	return itemValue, err
+}
+
+// parseMap parse each node to a map item, the item key and value are selected by
+// the `pagser_key` and `pagser_value` tags relative to the node:
+//	struct {
+//		Specs map[string]string `pagser:"table.spec tr" pagser_key:"th" pagser_value:"td"`
+//	}
+func (p *Pagser) parseMap(state *parseState, objRefValue reflect.Value, stackRefValues []reflect.Value, field *fieldPlan, fieldValue reflect.Value, node *goquery.Selection, path string) (err error) {
+	tag, keyTag, valueTag := field.tag, field.keyTag, field.valueTag
+	mapType := fieldValue.Type()
+	keyType := mapType.Key()
+	itemType := mapType.Elem()
+	itemKind := itemType.Kind()
+	mapValue := reflect.MakeMapWithSize(mapType, node.Size())
+	node.EachWithBreak(func(i int, subNode *goquery.Selection) bool {
+		if err = state.ctx.Err(); err != nil {
+			return false
+		}
+		var keyOut interface{}
+		keyOut, _, err = p.execTag(state, objRefValue, stackRefValues, keyTag, subNode)
+		if err != nil {
+			err = state.fail(indexFieldPath(path, strconv.Itoa(i)), tag, fmt.Errorf("key parse func error: %w", err))
+			return err == nil
+		}
+		if keyNode, ok := keyOut.(*goquery.Selection); ok {
+			keyOut = strings.TrimSpace(keyNode.Text())
+		}
+		keyValue := reflect.New(keyType).Elem()
+		err = p.setRefectValue(keyType.Kind(), keyValue, keyOut)
+		if err != nil {
+			err = state.fail(indexFieldPath(path, strconv.Itoa(i)), tag, fmt.Errorf("key set value error: %w", err))
+			return err == nil
+		}
+
+		itemPath := indexFieldPath(path, fmt.Sprint(keyOut))
+		var valueOut interface{}
+		valueOut, _, err = p.execTag(state, objRefValue, stackRefValues, valueTag, subNode)
+		if err != nil {
+			err = state.fail(itemPath, tag, fmt.Errorf("value parse func error: %w", err))
+			return err == nil
+		}
+		itemValue := reflect.New(itemType).Elem()
+		valueNode, isNode := valueOut.(*goquery.Selection)
+		switch {
+		case isNode && isUnmarshaler(itemType):
+			err = unmarshalSelection(itemValue, valueNode)
+		case isNode && p.isValueType(itemType):
+			err = p.setRefectValue(itemKind, itemValue, strings.TrimSpace(valueNode.Text()))
+		case isNode && itemKind == reflect.Struct:
+			err = p.doParse(state, itemValue.Addr().Interface(), stackRefValues, valueNode, itemPath)
+		case isNode && itemKind == reflect.Ptr && itemType.Elem().Kind() == reflect.Struct:
+			itemValue = reflect.New(itemType.Elem())
+			err = p.doParse(state, itemValue.Interface(), stackRefValues, valueNode, itemPath)
+		case isNode && itemKind == reflect.Slice:
+			texts := make([]string, 0, valueNode.Size())
+			valueNode.Each(func(i int, selection *goquery.Selection) {
+				texts = append(texts, strings.TrimSpace(selection.Text()))
 			})
-			if err != nil {
-				return err
+			err = p.setRefectValue(itemKind, itemValue, texts)
+		case isNode:
+			err = p.setRefectValue(itemKind, itemValue, strings.TrimSpace(valueNode.Text()))
+		default:
+			err = p.setRefectValue(itemKind, itemValue, valueOut)
+		}
+		if err != nil {
+			if _, ok := err.(*FieldError); !ok {
+				err = fmt.Errorf("value parser error: %w", err)
 			}
-			fieldValue.Set(slice)
-		case kind == reflect.Struct:
-			subModel := reflect.New(fieldType.Type)
-			err = p.doParse(subModel.Interface(), stackRefValues, node)
+			err = state.fail(itemPath, tag, err)
 			if err != nil {
-				return fmt.Errorf("tag=`%v` %#v parser error: %v", tagValue, subModel, err)
+				return false
 			}
-			fieldValue.Set(subModel.Elem())
-			//UnsafePointer
-			//Complex64
-			//Complex128
-			//Array
-			//Chan
-			//Func
-		default:
-			fieldValue.SetString(strings.TrimSpace(node.Text()))
 		}
+		mapValue.SetMapIndex(keyValue, itemValue)
+		return true
+	})
+	if err != nil {
+		return err
 	}
+	fieldValue.Set(mapValue)
 	return nil
 }
 
+// tagKey the cache key of tagTokenizer, the functions are split by the function symbol of Config
+type tagKey struct {
+	value      string
+	funcSymbol string
+}
+
+// getTag get tagTokenizer from cache, or create and cache it
+func (p *Pagser) getTag(tagValue string) (*tagTokenizer, error) {
+	key := tagKey{value: tagValue, funcSymbol: p.Config.FuncSymbol}
+	if cacheTag, ok := p.mapTags.Load(key); ok && cacheTag != nil {
+		return cacheTag.(*tagTokenizer), nil
+	}
+	tag, err := p.newTag(tagValue)
+	if err != nil {
+		return nil, err
+	}
+	p.mapTags.Store(key, tag)
+	return tag, nil
+}
+
+// execTag find the tag selector from selection, and execute the tag functions pipeline,
+// it returns a Selection if the tag has no functions or the last function returns Selection.
+// The fallback alternatives are executed in order until one yields a non-empty result.
+// It also returns the number of nodes matched by the selector of the used alternative.
+func (p *Pagser) execTag(state *parseState, objRefValue reflect.Value, stackRefValues []reflect.Value, tag *tagTokenizer, selection *goquery.Selection) (interface{}, int, error) {
+	outValue, matched, err := p.execSelectorFuncs(state, objRefValue, stackRefValues, tag, selection)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, fallback := range tag.Fallbacks {
+		if !needFallback(outValue) {
+			break
+		}
+		outValue, matched, err = p.execSelectorFuncs(state, objRefValue, stackRefValues, fallback, selection)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	return outValue, matched, nil
+}
+
+// execSelectorFuncs find the tag selector from selection, and execute the tag functions pipeline
+func (p *Pagser) execSelectorFuncs(state *parseState, objRefValue reflect.Value, stackRefValues []reflect.Value, tag *tagTokenizer, selection *goquery.Selection) (interface{}, int, error) {
+	node, err := tag.find(selection)
+	if err != nil {
+		return nil, 0, err
+	}
+	if state.onSelect != nil {
+		state.onSelect(tag, node)
+	}
+	if len(tag.Funcs) == 0 {
+		return node, node.Size(), nil
+	}
+	outValue, err := p.execFuncs(state, objRefValue, stackRefValues, tag, node)
+	return outValue, node.Size(), err
+}
+
+// execFuncs execute the functions pipeline of tag, each function gets the previous function output.
+func (p *Pagser) execFuncs(state *parseState, objRefValue reflect.Value, stackRefValues []reflect.Value, selTag *tagTokenizer, node *goquery.Selection) (interface{}, error) {
+	var outValue interface{} = node
+	for _, fn := range selTag.Funcs {
+		var err error
+		outValue, err = p.findAndExecFunc(state, objRefValue, stackRefValues, fn, outValue)
+		if state.onFunc != nil {
+			state.onFunc(fn, outValue, err)
+		}
+		if err != nil {
+			if !p.Config.CastError && errors.Is(err, ErrCast) {
+				//ignore cast error, keep zero value
+				return nil, nil
+			}
+			return nil, err
+		}
+	}
+	return outValue, nil
+}
+
 /**
 fieldType := refTypeElem.Field(i)
 fieldValue := refValueElem.Field(i)
 */
-func (p *Pagser) findAndExecFunc(objRefValue reflect.Value, stackRefValues []reflect.Value, selTag *tagTokenizer, node *goquery.Selection) (interface{}, error) {
-	if selTag.FuncName != "" {
-
+func (p *Pagser) findAndExecFunc(state *parseState, objRefValue reflect.Value, stackRefValues []reflect.Value, fn *tagFunc, input interface{}) (interface{}, error) {
+	node, isNode := input.(*goquery.Selection)
+	if isNode {
 		//call object method
-		callMethod := findMethod(objRefValue, selTag.FuncName)
+		callMethod := p.findMethod(objRefValue, fn.Name)
 		if callMethod.IsValid() {
 			//execute method
-			return execMethod(callMethod, selTag, node)
+			return execMethod(state.ctx, callMethod, fn, node)
 		}
 
 		//call root method
 		size := len(stackRefValues)
 		if size > 0 {
 			for i := size - 1; i >= 0; i-- {
-				callMethod = findMethod(stackRefValues[i], selTag.FuncName)
+				callMethod = p.findMethod(stackRefValues[i], fn.Name)
 				if callMethod.IsValid() {
 					//execute method
-					return execMethod(callMethod, selTag, node)
+					return execMethod(state.ctx, callMethod, fn, node)
 				}
 			}
 		}
 
 		//global function
-		if fn, ok := p.mapFuncs.Load(selTag.FuncName); ok {
-			cfn := fn.(CallFunc)
-			outValue, err := cfn(node, selTag.FuncParams...)
+		if fn.callFuncContext != nil {
+			outValue, err := fn.callFuncContext(state.ctx, node, fn.Params...)
 			if err != nil {
-				return nil, fmt.Errorf("call registered func %v error: %v", selTag.FuncName, err)
+				return nil, fmt.Errorf("call registered func %v error: %w", fn.Name, err)
 			}
 			return outValue, nil
 		}
+		if fn.callFunc != nil {
+			outValue, err := fn.callFunc(node, fn.Params...)
+			if err != nil {
+				return nil, fmt.Errorf("call registered func %v error: %w", fn.Name, err)
+			}
+			return outValue, nil
+		}
+	}
 
-		//not found method
-		return nil, fmt.Errorf("not found method %v", selTag.FuncName)
+	//value function
+	if fn.valueFunc != nil {
+		var value = input
+		if isNode {
+			value = strings.TrimSpace(node.Text())
+		}
+		outValue, err := fn.valueFunc(value, fn.Params...)
+		if err != nil {
+			return nil, fmt.Errorf("call value func %v error: %w", fn.Name, err)
+		}
+		return outValue, nil
 	}
-	return strings.TrimSpace(node.Text()), nil
+
+	if !isNode {
+		if fn.callFunc != nil || fn.callFuncContext != nil {
+			return nil, fmt.Errorf("func %v requires a selection, but previous output is %T", fn.Name, input)
+		}
+		if p.hasMethod(objRefValue, stackRefValues, fn.Name) {
+			return nil, fmt.Errorf("method %v requires a selection, but previous output is %T", fn.Name, input)
+		}
+	}
+
+	//not found method
+	return nil, fmt.Errorf("not found method %v", fn.Name)
 }
 
-func findMethod(objRefValue reflect.Value, funcName string) reflect.Value {
-	callMethod := objRefValue.MethodByName(funcName)
-	if callMethod.IsValid() {
-		return callMethod
+// hasMethod the struct or its parent structs has the method
+func (p *Pagser) hasMethod(objRefValue reflect.Value, stackRefValues []reflect.Value, funcName string) bool {
+	if p.findMethod(objRefValue, funcName).IsValid() {
+		return true
 	}
-	//call element method
-	return objRefValue.Elem().MethodByName(funcName)
+	for _, refValue := range stackRefValues {
+		if p.findMethod(refValue, funcName).IsValid() {
+			return true
+		}
+	}
+	return false
 }
 
-func execMethod(callMethod reflect.Value, selTag *tagTokenizer, node *goquery.Selection) (interface{}, error) {
-	callParams := make([]reflect.Value, 0)
-	callParams = append(callParams, reflect.ValueOf(node))
+// findMethod find the method of struct pointer by the method index of struct plan
+func (p *Pagser) findMethod(objRefValue reflect.Value, funcName string) reflect.Value {
+	if !objRefValue.IsValid() {
+		return reflect.Value{}
+	}
+	if p.noPlanCache {
+		return objRefValue.MethodByName(funcName)
+	}
+	plan := p.getPlan(objRefValue.Type().Elem())
+	if index, ok := plan.methods[funcName]; ok {
+		return objRefValue.Method(index)
+	}
+	return reflect.Value{}
+}
+
+func execMethod(ctx context.Context, callMethod reflect.Value, fn *tagFunc, node *goquery.Selection) (interface{}, error) {
+	callParams, err := methodCallParams(ctx, callMethod.Type(), fn, node)
+	if err != nil {
+		return nil, err
+	}
 
 	callReturns := callMethod.Call(callParams)
 	if len(callReturns) <= 0 {
-		return nil, fmt.Errorf("method %v not return any value", selTag.FuncName)
+		return nil, fmt.Errorf("method %v not return any value", fn.Name)
 	}
 	if len(callReturns) > 1 {
 		if err, ok := callReturns[len(callReturns)-1].Interface().(error); ok {
 			if err != nil {
-				return nil, fmt.Errorf("method %v return error: %v", selTag.FuncName, err)
+				return nil, fmt.Errorf("method %v return error: %w", fn.Name, err)
 			}
 		}
 	}
 	return callReturns[0].Interface(), nil
 }
 
-func (p Pagser) setRefectValue(kind reflect.Kind, fieldValue reflect.Value, v interface{}) (err error) {
+// methodCallParams convert tag function params to method arguments,
+// the first argument is node, or context and node, and the others are converted from tag params:
+//	func (d PageData) MyFunc(node *goquery.Selection, args ...string) (out interface{}, err error)
+//	func (d PageData) MyFunc(node *goquery.Selection, name string, index int, trim bool) (out interface{}, err error)
+//	func (d PageData) MyFunc(ctx context.Context, node *goquery.Selection, args ...string) (out interface{}, err error)
+func methodCallParams(ctx context.Context, methodType reflect.Type, fn *tagFunc, node *goquery.Selection) ([]reflect.Value, error) {
+	numIn := methodType.NumIn()
+	callParams := make([]reflect.Value, 0, len(fn.Params)+2)
+	first := 0
+	if numIn > 0 && methodType.In(0) == contextType {
+		callParams = append(callParams, reflect.ValueOf(&ctx).Elem())
+		first = 1
+	}
+	if numIn <= first || methodType.In(first) != reflect.TypeOf(node) {
+		return nil, fmt.Errorf("method %v first argument must be *goquery.Selection", fn.Name)
+	}
+	fixedNum := numIn - first - 1
+	if methodType.IsVariadic() {
+		fixedNum--
+	}
+	if len(fn.Params) < fixedNum || (!methodType.IsVariadic() && len(fn.Params) > fixedNum) {
+		return nil, fmt.Errorf("method %v expects %v arguments, but got %v", fn.Name, fixedNum, len(fn.Params))
+	}
+
+	callParams = append(callParams, reflect.ValueOf(node))
+	for i, param := range fn.Params {
+		var paramType reflect.Type
+		if i < fixedNum {
+			paramType = methodType.In(first + i + 1)
+		} else {
+			paramType = methodType.In(numIn - 1).Elem()
+		}
+		paramValue, err := toParamValue(param, paramType)
+		if err != nil {
+			return nil, fmt.Errorf("method %v argument %v: %v", fn.Name, i+1, err)
+		}
+		callParams = append(callParams, paramValue)
+	}
+	return callParams, nil
+}
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// toParamValue convert tag function param to the argument type
+func toParamValue(param string, paramType reflect.Type) (reflect.Value, error) {
+	value := reflect.New(paramType).Elem()
+	kind := paramType.Kind()
+	switch {
+	case kind == reflect.String:
+		value.SetString(param)
+	case kind == reflect.Bool:
+		kv, err := cast.ToBoolE(strings.TrimSpace(param))
+		if err != nil {
+			return value, fmt.Errorf("`%v` is not %v", param, paramType)
+		}
+		value.SetBool(kv)
+	case kind >= reflect.Int && kind <= reflect.Int64:
+		kv, err := cast.ToInt64E(strings.TrimSpace(param))
+		if err != nil || value.OverflowInt(kv) {
+			return value, fmt.Errorf("`%v` is not %v", param, paramType)
+		}
+		value.SetInt(kv)
+	case kind >= reflect.Uint && kind <= reflect.Uintptr:
+		kv, err := cast.ToUint64E(strings.TrimSpace(param))
+		if err != nil || value.OverflowUint(kv) {
+			return value, fmt.Errorf("`%v` is not %v", param, paramType)
+		}
+		value.SetUint(kv)
+	case kind == reflect.Float32 || kind == reflect.Float64:
+		kv, err := cast.ToFloat64E(strings.TrimSpace(param))
+		if err != nil || value.OverflowFloat(kv) {
+			return value, fmt.Errorf("`%v` is not %v", param, paramType)
+		}
+		value.SetFloat(kv)
+	default:
+		return value, fmt.Errorf("not support argument type %v", paramType)
+	}
+	return value, nil
+}
+
+// isEmptyValue the output is nil, empty Selection, blank string, or empty slice and map
+func isEmptyValue(v interface{}) bool {
+	switch value := v.(type) {
+	case nil:
+		return true
+	case *goquery.Selection:
+		return value.Size() == 0
+	case string:
+		return strings.TrimSpace(value) == ""
+	}
+	refValue := reflect.ValueOf(v)
+	switch refValue.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return refValue.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return refValue.IsNil()
+	}
+	return false
+}
+
+// needFallback the output of alternative is empty, or the matched nodes have no text and child elements,
+// eg: `<span></span>`, then the next fallback alternative is executed
+func needFallback(v interface{}) bool {
+	if node, ok := v.(*goquery.Selection); ok && node.Size() > 0 {
+		return strings.TrimSpace(node.Text()) == "" && node.Children().Size() == 0
+	}
+	return isEmptyValue(v)
+}
+
+// isValueType the type is set from node text or function output, not parsed as nested struct.
+// The struct with tagged fields is parsed as nested struct even if it implements encoding.TextUnmarshaler.
+func (p *Pagser) isValueType(t reflect.Type) bool {
+	return t == timeType || t == timePtrType || (isTextUnmarshaler(t) && !hasTaggedFields(t, p.Config.TagName))
+}
+
+// isNodesType the type is parsed from the matched nodes by parseField, not the text of nodes
+func (p *Pagser) isNodesType(t reflect.Type) bool {
+	if isUnmarshaler(t) {
+		return true
+	}
+	if p.isValueType(t) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Struct, reflect.Map:
+		return true
+	}
+	return false
+}
+
+func (p *Pagser) setRefectValue(kind reflect.Kind, fieldValue reflect.Value, v interface{}) (err error) {
 	//set value
 	switch {
+	case fieldValue.Type() == timeType || fieldValue.Type() == timePtrType:
+		kv, err := toTimeE(v, time.UTC)
+		if err != nil {
+			if p.Config.CastError {
+				return err
+			}
+			return nil
+		}
+		if kind == reflect.Ptr {
+			fieldValue.Set(reflect.ValueOf(&kv))
+		} else {
+			fieldValue.Set(reflect.ValueOf(kv))
+		}
+	case isTextUnmarshaler(fieldValue.Type()):
+		if v == nil {
+			return nil
+		}
+		text, err := cast.ToStringE(v)
+		if err != nil {
+			if p.Config.CastError {
+				return err
+			}
+			return nil
+		}
+		return unmarshalText(fieldValue, text)
+	case isUnmarshaler(fieldValue.Type()) && (kind == reflect.Struct || kind == reflect.Ptr):
+		if v == nil {
+			return nil
+		}
+		return fmt.Errorf("%v unmarshal from selection, but got %T value", fieldValue.Type(), v)
 	//Bool
 	case kind == reflect.Bool:
 		if p.Config.CastError {
@@ -301,6 +912,23 @@ func (p Pagser) setRefectValue(kind reflect.Kind, fieldValue reflect.Value, v in
 		} else {
 			fieldValue.SetString(cast.ToString(v))
 		}
+	case kind == reflect.Slice && p.isValueType(fieldValue.Type().Elem()):
+		sliceType := fieldValue.Type().Elem()
+		list := reflect.ValueOf(v)
+		if v == nil || (list.Kind() != reflect.Slice && list.Kind() != reflect.Array) {
+			if p.Config.CastError {
+				return fmt.Errorf("unable to cast %#v of type %T to %v", v, v, fieldValue.Type())
+			}
+			return nil
+		}
+		slice := reflect.MakeSlice(fieldValue.Type(), list.Len(), list.Len())
+		for i := 0; i < list.Len(); i++ {
+			err = p.setRefectValue(sliceType.Kind(), slice.Index(i), list.Index(i).Interface())
+			if err != nil {
+				return err
+			}
+		}
+		fieldValue.Set(slice)
 	case kind == reflect.Slice || kind == reflect.Array:
 		sliceType := fieldValue.Type().Elem()
 		itemKind := sliceType.Kind()
@@ -381,6 +1009,9 @@ func (p Pagser) setRefectValue(kind reflect.Kind, fieldValue reflect.Value, v in
 	//case kind == reflect.Interface:
 	//	fieldValue.Set(reflect.ValueOf(v))
 	default:
+		if v == nil {
+			return nil
+		}
 		fieldValue.Set(reflect.ValueOf(v))
 		//return fmt.Errorf("not support type %v", kind)
 	}
@@ -1,11 +1,16 @@
 package pagser
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"reflect"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
 )
@@ -333,3 +338,453 @@ func TestPagser_RegisterFunc(t *testing.T) {
 
 	wg.Wait()
 }
+
+type PipelineData struct {
+	H1Lower     string   `pagser:"h1->text()->toLower()"`
+	H1Upper     string   `pagser:"h1->toUpper()"`
+	FirstHref   string   `pagser:".navlink a->eq(1)->attr(href)->trimPrefix('/list/')"`
+	ParentID    int      `pagser:".navlink a->last()->parent()->attr(id)"`
+	Words       []string `pagser:".words->text()->split('|')->toLower()"`
+	WordsJoin   string   `pagser:".words->textSplit('|')->join('-')"`
+	GroupTitles []string `pagser:".group li->first()->parents('.group')->child('h2')->eachText()"`
+	EmailGroup  struct {
+		Title string `pagser:"h2"`
+	} `pagser:".item[name='email']->first()->parents('.group')"`
+	StructPipe string `pagser:"h1->MyStructFunc()->toUpper()"`
+}
+
+func (pd PipelineData) MyStructFunc(selection *goquery.Selection, args ...string) (out interface{}, err error) {
+	return "Struct-" + selection.Text(), nil
+}
+
+type PipelineMethodData struct {
+	Value string `pagser:"h1->text()->MyStructFunc()"`
+}
+
+func (pd PipelineMethodData) MyStructFunc(selection *goquery.Selection, args ...string) (out interface{}, err error) {
+	return selection.Text(), nil
+}
+
+func TestParsePipeline(t *testing.T) {
+	p := New()
+
+	var data PipelineData
+	err := p.Parse(&data, rawParseHtml)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := PipelineData{
+		H1Lower:     "pagser h1 title",
+		H1Upper:     "PAGSER H1 TITLE",
+		FirstHref:   "web",
+		ParentID:    4,
+		Words:       []string{"a", "b", "c", "d"},
+		WordsJoin:   "A-B-C-D",
+		GroupTitles: []string{"Email"},
+		StructPipe:  "STRUCT-PAGSER H1 TITLE",
+	}
+	want.EmailGroup.Title = "Email"
+	if prettyJson(data) != prettyJson(want) {
+		t.Fatalf("want %v, but got %v", prettyJson(want), prettyJson(data))
+	}
+
+	var errData struct {
+		Value string `pagser:"h1->text()->attr(href)"`
+	}
+	if err := p.Parse(&errData, rawParseHtml); err == nil {
+		t.Fatal("selection function after value function must return error")
+	}
+
+	var errMethod PipelineMethodData
+	if err = p.Parse(&errMethod, rawParseHtml); err == nil || !strings.Contains(err.Error(), "method MyStructFunc requires a selection") {
+		t.Fatalf("struct method after value function want requires selection error, but got %v", err)
+	}
+
+	var quoted struct {
+		Value string `pagser:"h1->text()->replace(' ', '->')->replace('->H1', '')"`
+	}
+	if err = p.Parse(&quoted, rawParseHtml); err != nil || quoted.Value != "Pagser->Title" {
+		t.Fatalf("function symbol in quoted argument want `Pagser->Title`, but got %v, error %v", quoted.Value, err)
+	}
+}
+
+type MethodArgsParent struct {
+	Sub MethodArgsData `pagser:"body"`
+}
+
+func (d MethodArgsParent) ParentArg(node *goquery.Selection, rate float64) (out interface{}, err error) {
+	return rate * 2, nil
+}
+
+type MethodArgsData struct {
+	VariadicValue  string  `pagser:"h1->Variadic('a', 'b')"`
+	TypedValue     string  `pagser:"h1->Typed('x', 2, true, 1.5)"`
+	TypedVarValue  int     `pagser:"h1->TypedVariadic(1, 2, 3)"`
+	NoArgsValue    string  `pagser:"h1->NoArgs()"`
+	ParentArgValue float64 `pagser:"h1->ParentArg(2.5)"`
+}
+
+func (d MethodArgsData) Variadic(node *goquery.Selection, args ...string) (out interface{}, err error) {
+	return strings.Join(args, "|"), nil
+}
+
+func (d MethodArgsData) Typed(node *goquery.Selection, name string, count int, upper bool, rate float64) (out interface{}, err error) {
+	value := fmt.Sprintf("%v-%v-%v", name, count, rate)
+	if upper {
+		value = strings.ToUpper(value)
+	}
+	return value, nil
+}
+
+func (d MethodArgsData) TypedVariadic(node *goquery.Selection, nums ...int) (out interface{}, err error) {
+	sum := 0
+	for _, n := range nums {
+		sum += n
+	}
+	return sum, nil
+}
+
+func (d MethodArgsData) NoArgs(node *goquery.Selection) (out interface{}, err error) {
+	return "no-args", nil
+}
+
+func TestParseMethodArgs(t *testing.T) {
+	p := New()
+
+	var data MethodArgsParent
+	err := p.Parse(&data, rawParseHtml)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := MethodArgsData{
+		VariadicValue:  "a|b",
+		TypedValue:     "X-2-1.5",
+		TypedVarValue:  6,
+		NoArgsValue:    "no-args",
+		ParentArgValue: 5,
+	}
+	if data.Sub != want {
+		t.Fatalf("want %v, but got %v", prettyJson(want), prettyJson(data.Sub))
+	}
+
+	errTags := []string{
+		//too many arguments
+		"h1->NoArgs('a')",
+		//too few arguments
+		"h1->Typed('x', 2, true)",
+		//not int
+		"h1->Typed('x', two, true, 1.5)",
+		//not bool
+		"h1->Typed('x', 2, yes, 1.5)",
+		//variadic not int
+		"h1->TypedVariadic(1, a)",
+	}
+	for _, tagValue := range errTags {
+		var errData struct {
+			Sub MethodArgsData `pagser:"body"`
+		}
+		tag, err := p.newTag(tagValue)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = p.findAndExecFunc(p.newParseState(context.Background()), reflect.ValueOf(&errData.Sub), nil, tag.Funcs[0], newTewSelection(`<h1>a</h1>`))
+		if err == nil {
+			t.Errorf("tag `%v` want an error", tagValue)
+		}
+	}
+}
+
+const rawMapHtml = `
+<html>
+<body>
+	<table class="spec">
+		<tr><th>Color</th><td>Red</td></tr>
+		<tr><th>Weight</th><td>1.5</td></tr>
+	</table>
+	<dl>
+		<dt>1</dt><dd><span class="name">One</span><span class="tag">a</span><span class="tag">b</span></dd>
+		<dt>2</dt><dd><span class="name">Two</span><span class="tag">c</span></dd>
+	</dl>
+</body>
+</html>
+`
+
+type MapData struct {
+	Specs    map[string]string   `pagser:"table.spec tr" pagser_key:"th" pagser_value:"td"`
+	Numbers  map[string]float64  `pagser:"table.spec tr:last-child" pagser_key:"th->toLower()" pagser_value:"td"`
+	Items    map[int]MapItem     `pagser:"dl dt" pagser_key:"" pagser_value:"->next('dd')"`
+	ItemPtrs map[int]*MapItem    `pagser:"dl dt" pagser_value:"->next('dd')"`
+	Tags     map[string][]string `pagser:"dl dd" pagser_key:".name" pagser_value:".tag"`
+	Names    map[int]string      `pagser:"dl dt" pagser_value:"->next()->child('.name')->text()->toUpper()"`
+}
+
+type MapItem struct {
+	Name string   `pagser:".name"`
+	Tags []string `pagser:".tag"`
+}
+
+func TestParseMap(t *testing.T) {
+	p := New()
+
+	var data MapData
+	err := p.Parse(&data, rawMapHtml)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := MapData{
+		Specs:   map[string]string{"Color": "Red", "Weight": "1.5"},
+		Numbers: map[string]float64{"weight": 1.5},
+		Items: map[int]MapItem{
+			1: {Name: "One", Tags: []string{"a", "b"}},
+			2: {Name: "Two", Tags: []string{"c"}},
+		},
+		ItemPtrs: map[int]*MapItem{
+			1: {Name: "One", Tags: []string{"a", "b"}},
+			2: {Name: "Two", Tags: []string{"c"}},
+		},
+		Tags:  map[string][]string{"One": {"a", "b"}, "Two": {"c"}},
+		Names: map[int]string{1: "ONE", 2: "TWO"},
+	}
+	if prettyJson(data) != prettyJson(want) {
+		t.Fatalf("want %v, but got %v", prettyJson(want), prettyJson(data))
+	}
+
+	cfg := DefaultConfig()
+	cfg.CastError = true
+	p, err = NewWithConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var errData struct {
+		Specs map[int]string `pagser:"table.spec tr" pagser_key:"th" pagser_value:"td"`
+	}
+	err = p.Parse(&errData, rawMapHtml)
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Path != "Specs[0]" {
+		t.Fatalf("key `Color` is not int, must return error of path `Specs[0]`, but got %v", err)
+	}
+}
+
+const rawFallbackHtml = `
+<html>
+<head>
+	<meta property="og:title" content="OG Title">
+</head>
+<body>
+	<div class="product-name"> </div>
+	<ul class="tags"><li>a</li><li>b</li></ul>
+	<div class="info"><span class="name">Info</span></div>
+	<span class="subtitle"></span>
+	<div class="cover"><img src="/cover.png"></div>
+</body>
+</html>
+`
+
+type FallbackData struct {
+	Title      string   `pagser:"h1.title || .product-name->text() || meta[property='og:title']->attr(content)"`
+	FirstMatch string   `pagser:".product-name || .tags li->first()"`
+	Tags       []string `pagser:".keywords->eachText() || .tags li->eachText()"`
+	Info       struct {
+		Name string `pagser:".name"`
+	} `pagser:".detail || .info"`
+	Missing  string `pagser:".a || .b->textEmpty('none')"`
+	Subtitle string `pagser:".subtitle || .info .name"`
+	Cover    struct {
+		Src string `pagser:"img->attr(src)"`
+	} `pagser:".cover || .info"`
+}
+
+func TestParseFallback(t *testing.T) {
+	p := New()
+
+	var data FallbackData
+	err := p.Parse(&data, rawFallbackHtml)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := FallbackData{
+		Title:      "OG Title",
+		FirstMatch: "a",
+		Tags:       []string{"a", "b"},
+		Missing:    "none",
+		Subtitle:   "Info",
+	}
+	want.Info.Name = "Info"
+	want.Cover.Src = "/cover.png"
+	if prettyJson(data) != prettyJson(want) {
+		t.Fatalf("want %v, but got %v", prettyJson(want), prettyJson(data))
+	}
+}
+
+const rawContextHtml = `
+<html>
+<body>
+	<h1>Title</h1>
+	<ul>
+		<li><a href="/a">A</a></li>
+		<li><a href="/b">B</a></li>
+		<li><a href="/c">C</a></li>
+	</ul>
+</body>
+</html>
+`
+
+type contextKey string
+
+type ContextData struct {
+	Title  string        `pagser:"h1->ctxValue()"`
+	Method string        `pagser:"h1->CtxMethod('x')"`
+	Items  []ContextItem `pagser:"li"`
+}
+
+func (d ContextData) CtxMethod(ctx context.Context, node *goquery.Selection, prefix string) (out interface{}, err error) {
+	return fmt.Sprintf("%v-%v", prefix, ctx.Value(contextKey("name"))), nil
+}
+
+type ContextItem struct {
+	Name string `pagser:"a->cancelAt('B')"`
+}
+
+func TestParseContext(t *testing.T) {
+	p := New()
+	p.RegisterFuncContext("ctxValue", func(ctx context.Context, node *goquery.Selection, args ...string) (out interface{}, err error) {
+		return fmt.Sprintf("%v-%v", node.Text(), ctx.Value(contextKey("name"))), nil
+	})
+	var cancel context.CancelFunc
+	p.RegisterFuncContext("cancelAt", func(ctx context.Context, node *goquery.Selection, args ...string) (out interface{}, err error) {
+		if cancel != nil && node.Text() == args[0] {
+			cancel()
+		}
+		return node.Text(), nil
+	})
+
+	ctx := context.WithValue(context.Background(), contextKey("name"), "ctx")
+	var data ContextData
+	if err := p.ParseContext(ctx, &data, rawContextHtml); err != nil {
+		t.Fatal(err)
+	}
+	if data.Title != "Title-ctx" || data.Method != "x-ctx" || len(data.Items) != 3 {
+		t.Fatalf("unexpected data: %v", prettyJson(data))
+	}
+
+	//cancel in the second item, the third item is not parsed
+	for _, continueOnError := range []bool{false, true} {
+		p.Config.ContinueOnError = continueOnError
+		cancelCtx, cancelFunc := context.WithCancel(ctx)
+		defer cancelFunc()
+		cancel = cancelFunc
+		var cancelData ContextData
+		err := p.ParseContext(cancelCtx, &cancelData, rawContextHtml)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("continueOnError=%v: want context canceled, but got %v", continueOnError, err)
+		}
+		if cancelData.Items != nil {
+			t.Fatalf("continueOnError=%v: items must not be set, but got %v", continueOnError, prettyJson(cancelData.Items))
+		}
+	}
+	p.Config.ContinueOnError = false
+
+	//done before parse
+	cancelCtx, cancelFunc := context.WithCancel(ctx)
+	cancelFunc()
+	if err := p.ParseContext(cancelCtx, &data, rawContextHtml); !errors.Is(err, context.Canceled) {
+		t.Fatalf("want context canceled, but got %v", err)
+	}
+	deadlineCtx, deadlineCancel := context.WithTimeout(ctx, -time.Second)
+	defer deadlineCancel()
+	if _, err := p.ParseSelectionWithRulesContext(deadlineCtx, newTewSelection(rawContextHtml), Rules{"title": {Selector: "h1"}}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want deadline exceeded, but got %v", err)
+	}
+}
+
+type ParallelItem struct {
+	ID    int      `pagser:"->attr(data-id)"`
+	Name  string   `pagser:"a->text()"`
+	Price int      `pagser:".price"`
+	Tags  []string `pagser:"li" pagser_opts:"parallel=2"`
+}
+
+type ParallelData struct {
+	Items []ParallelItem  `pagser:".item"`
+	Ptrs  []*ParallelItem `pagser:".item" pagser_opts:"parallel"`
+	Names []string        `pagser:".item a"`
+}
+
+func parallelHtml(size int, badPrices ...int) string {
+	html := strings.Builder{}
+	html.WriteString("<html><body>")
+	for i := 0; i < size; i++ {
+		price := strconv.Itoa(i)
+		for _, bad := range badPrices {
+			if bad == i {
+				price = "bad"
+			}
+		}
+		fmt.Fprintf(&html, `<div class="item" data-id="%v"><a>Item %v</a><span class="price">%v</span><ul><li>a%v</li><li>b%v</li></ul></div>`,
+			i, i, price, i, i)
+	}
+	html.WriteString("</body></html>")
+	return html.String()
+}
+
+func TestParseParallel(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.CastError = true
+	sequential, err := NewWithConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Parallelism = 4
+	parallel, err := NewWithConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	html := parallelHtml(100)
+	var want, got ParallelData
+	if err := sequential.Parse(&want, html); err != nil {
+		t.Fatal(err)
+	}
+	if err := parallel.Parse(&got, html); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Items) != 100 || got.Items[99].Name != "Item 99" || got.Ptrs[42].Tags[1] != "b42" {
+		t.Fatalf("unexpected data: %v", prettyJson(got.Items[99]))
+	}
+	if prettyJson(got) != prettyJson(want) {
+		t.Fatal("parallel data is not the same as sequential data")
+	}
+
+	//the errors are the same as sequential parsing
+	html = parallelHtml(100, 7, 3, 60)
+	for _, continueOnError := range []bool{false, true} {
+		sequential.Config.ContinueOnError = continueOnError
+		parallel.Config.ContinueOnError = continueOnError
+		wantErr := sequential.Parse(&ParallelData{}, html)
+		gotErr := parallel.Parse(&ParallelData{}, html)
+		if wantErr == nil || gotErr == nil || wantErr.Error() != gotErr.Error() {
+			t.Fatalf("continueOnError=%v: want error %v, but got %v", continueOnError, wantErr, gotErr)
+		}
+	}
+	var fieldErr *FieldError
+	if !errors.As(parallel.Parse(&ParallelData{}, html), &fieldErr) || fieldErr.Path != "Items[3].Price" {
+		t.Fatalf("want error of Items[3].Price, but got %v", fieldErr)
+	}
+
+	//canceled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := parallel.ParseContext(ctx, &ParallelData{}, html); !errors.Is(err, context.Canceled) {
+		t.Fatalf("want context canceled, but got %v", err)
+	}
+
+	if _, err := newTagOptions("parallel=0"); err == nil {
+		t.Fatal("parallel=0 must be invalid")
+	}
+	var notSlice struct {
+		Item ParallelItem `pagser:".item" pagser_opts:"parallel"`
+	}
+	if err := parallel.Parse(&notSlice, html); err == nil || !strings.Contains(err.Error(), "requires slice field") {
+		t.Fatalf("parallel option of struct field want error, but got %v", err)
+	}
+}
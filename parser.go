@@ -0,0 +1,139 @@
+package pagser
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Parser is the interface implemented by types that parse themselves from a selection without reflection,
+// ParseSelection calls ParsePagser if the struct implements Parser. The struct is parsed by reflection if
+// the context can be canceled, the base url is set by context, ParseWithBaseURL or ParseResponse, Config.CastError or Config.ContinueOnError
+// is set, a builtin function is replaced by RegisterFunc, or PagserTags returns the other tag name or function symbol
+// than Config, the generated code does not depend on them.
+//
+// The ParsePagser method is generated from the struct tags by cmd/pagser-gen:
+//	//go:generate go run github.com/foolin/pagser/cmd/pagser-gen -type PageData
+//	type PageData struct {
+//		Title string `pagser:"title"`
+//	}
+type Parser interface {
+	ParsePagser(sel *goquery.Selection) error
+	PagserTags() (tagName string, funcSymbol string) //tag name and function symbol of the tags ParsePagser is generated from
+}
+
+// TagInfo the parsed struct tag, eg: `.item a->attr(href)->trim() || a->text()`
+type TagInfo struct {
+	Value     string     //raw tag value
+	Selector  string     //selector, with `xpath:` prefix if it is xpath
+	Funcs     []*TagFunc //functions pipeline
+	Fallbacks []*TagInfo //fallback alternatives
+}
+
+// TagFunc the function call of struct tag, eg: `attr(href)`
+type TagFunc struct {
+	Name   string
+	Params []string
+}
+
+// ParseTag parse the struct tag value by the grammar of Config.FuncSymbol
+func (p *Pagser) ParseTag(tagValue string) (*TagInfo, error) {
+	tag, err := p.newTag(tagValue)
+	if err != nil {
+		return nil, err
+	}
+	return newTagInfo(tag), nil
+}
+
+func newTagInfo(tag *tagTokenizer) *TagInfo {
+	info := &TagInfo{
+		Value:    tag.Value,
+		Selector: tag.Selector,
+		Funcs:    make([]*TagFunc, len(tag.Funcs)),
+	}
+	for i, fn := range tag.Funcs {
+		info.Funcs[i] = &TagFunc{Name: fn.Name, Params: fn.Params}
+	}
+	for _, fallback := range tag.Fallbacks {
+		info.Fallbacks = append(info.Fallbacks, newTagInfo(fallback))
+	}
+	return info
+}
+
+// The helpers below are used by the code generated by cmd/pagser-gen.
+
+// NewFieldError returns the error of the field, if err is a FieldError of nested struct, the path is joined.
+func NewFieldError(path string, tag string, selector string, funcs string, err error) *FieldError {
+	if fieldErr, ok := err.(*FieldError); ok {
+		fieldErr.Path = joinFieldPath(path, fieldErr.Path)
+		return fieldErr
+	}
+	return &FieldError{Path: path, Tag: tag, Selector: selector, Func: funcs, Err: err}
+}
+
+// OutputNode returns the Selection output of the previous function, fn is the function name to call
+func OutputNode(out interface{}, fn string) (*goquery.Selection, error) {
+	node, ok := out.(*goquery.Selection)
+	if !ok {
+		return nil, fmt.Errorf("func %v requires a selection, but previous output is %T", fn, out)
+	}
+	return node, nil
+}
+
+// OutputValue returns the value of function output, it is the trimmed text if output is Selection
+func OutputValue(out interface{}) interface{} {
+	if node, ok := out.(*goquery.Selection); ok {
+		return strings.TrimSpace(node.Text())
+	}
+	return out
+}
+
+// OutputList returns the items of function output, it is the trimmed text of each node if output is Selection
+func OutputList(out interface{}) []interface{} {
+	switch v := out.(type) {
+	case nil:
+		return nil
+	case *goquery.Selection:
+		list := make([]interface{}, v.Size())
+		for i := range list {
+			list[i] = strings.TrimSpace(v.Eq(i).Text())
+		}
+		return list
+	case []interface{}:
+		return v
+	case []string:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = item
+		}
+		return list
+	}
+	refValue := reflect.ValueOf(out)
+	if refValue.Kind() != reflect.Slice && refValue.Kind() != reflect.Array {
+		return []interface{}{out}
+	}
+	list := make([]interface{}, refValue.Len())
+	for i := range list {
+		list[i] = refValue.Index(i).Interface()
+	}
+	return list
+}
+
+// IsEmptyOutput the output is nil, empty Selection, blank string, or empty slice and map
+func IsEmptyOutput(out interface{}) bool {
+	return isEmptyValue(out)
+}
+
+// NeedFallback the output is empty, or the matched nodes have no text and child elements,
+// the next fallback alternative of tag is executed
+func NeedFallback(out interface{}) bool {
+	return needFallback(out)
+}
+
+// ToTimeE casts the function output to time.Time in UTC, like the time.Time fields
+func ToTimeE(out interface{}) (time.Time, error) {
+	return toTimeE(out, time.UTC)
+}
@@ -0,0 +1,101 @@
+package pagser
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+type ParserData struct {
+	Title  string `pagser:"title" css:"title"`
+	Parsed bool
+}
+
+func (d *ParserData) ParsePagser(sel *goquery.Selection) error {
+	d.Title = sel.Find("title").Text()
+	d.Parsed = true
+	return nil
+}
+
+func (d *ParserData) PagserTags() (string, string) {
+	return "pagser", "->"
+}
+
+func TestParseParser(t *testing.T) {
+	var data ParserData
+	if err := New().Parse(&data, rawPagserHtml); err != nil {
+		t.Fatal(err)
+	}
+	if !data.Parsed || data.Title != "Pagser Example" {
+		t.Errorf("ParsePagser want called, but got %v", prettyJson(data))
+	}
+
+	reflection := map[string]func(p *Pagser) error{
+		"base url": func(p *Pagser) error {
+			return p.ParseWithBaseURL(&data, rawPagserHtml, "https://example.com/")
+		},
+		"context": func(p *Pagser) error {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			return p.ParseContext(ctx, &data, rawPagserHtml)
+		},
+		"cast error": func(p *Pagser) error {
+			p.Config.CastError = true
+			return p.Parse(&data, rawPagserHtml)
+		},
+		"continue on error": func(p *Pagser) error {
+			p.Config.ContinueOnError = true
+			return p.Parse(&data, rawPagserHtml)
+		},
+		"tag name": func(p *Pagser) error {
+			p.Config.TagName = "css"
+			return p.Parse(&data, rawPagserHtml)
+		},
+		"function symbol": func(p *Pagser) error {
+			p.Config.FuncSymbol = "|"
+			return p.Parse(&data, rawPagserHtml)
+		},
+		"builtin replaced": func(p *Pagser) error {
+			p.RegisterValueFunc("toUpper", func(value interface{}, args ...string) (out interface{}, err error) {
+				return value, nil
+			})
+			return p.Parse(&data, rawPagserHtml)
+		},
+	}
+	for name, parse := range reflection {
+		data = ParserData{}
+		if err := parse(New()); err != nil {
+			t.Fatal(err)
+		}
+		if data.Parsed || data.Title != "Pagser Example" {
+			t.Errorf("%v: want parsed by reflection, but got %v", name, prettyJson(data))
+		}
+	}
+}
+
+func TestParseTag(t *testing.T) {
+	tag, err := New().ParseTag(".item a->attr(href)->trim() || a->text()")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag.Selector != ".item a" || len(tag.Funcs) != 2 || tag.Funcs[0].Name != "attr" || tag.Funcs[0].Params[0] != "href" {
+		t.Errorf("unexpected tag: %v", prettyJson(tag))
+	}
+	if len(tag.Fallbacks) != 1 || tag.Fallbacks[0].Selector != "a" || tag.Fallbacks[0].Funcs[0].Name != "text" {
+		t.Errorf("unexpected fallbacks: %v", prettyJson(tag.Fallbacks))
+	}
+	if _, err = New().ParseTag("a->attr("); err == nil {
+		t.Error("invalid tag want error")
+	}
+}
+
+func TestNewFieldError(t *testing.T) {
+	nested := NewFieldError("Name", "a", "a", "", errFailMethod)
+	err := NewFieldError("Items[1]", ".item", ".item", "", nested)
+	if err.Path != "Items[1].Name" || err.Tag != "a" || !errors.Is(err, errFailMethod) {
+		t.Errorf("unexpected field error: %#v", err)
+	}
+}
@@ -0,0 +1,119 @@
+package pagser
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// structPlan the precompiled parse plan of a struct type, it is created once for each type
+type structPlan struct {
+	fields  []*fieldPlan
+	methods map[string]int //method name => method index of the struct pointer type
+}
+
+// fieldPlan the precompiled parse plan of a struct field
+type fieldPlan struct {
+	index    int
+	name     string
+	typ      reflect.Type
+	tag      *tagTokenizer
+	keyTag   *tagTokenizer //map key tag
+	valueTag *tagTokenizer //map value tag
+	opts     *tagOptions   //nil if the field has no options tag
+	nodes    bool          //field is parsed from the matched nodes, not their text, eg: struct, slice, pointer, map and Unmarshaler
+	err      error         //invalid tag error, returned when the field is parsed
+}
+
+// planKey the cache key of structPlan, the plan depends on the tag name and function symbol of Config
+type planKey struct {
+	structType reflect.Type
+	tagName    string
+	funcSymbol string
+}
+
+// getPlan get structPlan of struct type from cache, or compile and cache it
+func (p *Pagser) getPlan(structType reflect.Type) *structPlan {
+	if p.noPlanCache {
+		return p.newPlan(structType)
+	}
+	key := planKey{structType: structType, tagName: p.Config.TagName, funcSymbol: p.Config.FuncSymbol}
+	if cachePlan, ok := p.plans.Load(key); ok {
+		return cachePlan.(*structPlan)
+	}
+	cachePlan, _ := p.plans.LoadOrStore(key, p.newPlan(structType))
+	return cachePlan.(*structPlan)
+}
+
+// newPlan compile the struct tags and methods of struct type
+func (p *Pagser) newPlan(structType reflect.Type) *structPlan {
+	plan := &structPlan{
+		fields:  make([]*fieldPlan, 0, structType.NumField()),
+		methods: make(map[string]int),
+	}
+	ptrType := reflect.PtrTo(structType)
+	for i := 0; i < ptrType.NumMethod(); i++ {
+		plan.methods[ptrType.Method(i).Name] = i
+	}
+	for i := 0; i < structType.NumField(); i++ {
+		fieldType := structType.Field(i)
+		tagValue, tagOk := fieldType.Tag.Lookup(p.Config.TagName)
+		if !tagOk {
+			if p.Config.Debug {
+				fmt.Printf("[INFO] not found tag name=[%v] in field: %v, eg: `%v:\".navlink a->attr(href)\"`\n",
+					p.Config.TagName, fieldType.Name, p.Config.TagName)
+			}
+			continue
+		}
+		if tagValue == ignoreSymbol {
+			continue
+		}
+		plan.fields = append(plan.fields, p.newFieldPlan(i, fieldType, tagValue))
+	}
+	return plan
+}
+
+func (p *Pagser) newFieldPlan(index int, fieldType reflect.StructField, tagValue string) *fieldPlan {
+	field := &fieldPlan{
+		index: index,
+		name:  fieldType.Name,
+		typ:   fieldType.Type,
+		tag:   &tagTokenizer{Value: tagValue},
+		nodes: p.isNodesType(fieldType.Type),
+	}
+	tag, err := p.getTag(tagValue)
+	if err != nil {
+		field.err = err
+		return field
+	}
+	field.tag = tag
+	if optsValue, ok := fieldType.Tag.Lookup(p.Config.TagName + optionsTagSuffix); ok {
+		field.opts, field.err = p.getOptions(optsValue)
+		if field.err != nil {
+			return field
+		}
+		if field.opts.Parallel > 0 && fieldType.Type.Kind() != reflect.Slice {
+			field.err = fmt.Errorf("options `%v` is invalid: option `parallel` requires slice field, but field type is %v", optsValue, fieldType.Type)
+			return field
+		}
+	}
+	if fieldType.Type.Kind() == reflect.Map {
+		field.keyTag, field.err = p.getTag(fieldType.Tag.Get(p.Config.TagName + mapKeyTagSuffix))
+		if field.err != nil {
+			return field
+		}
+		field.valueTag, field.err = p.getTag(fieldType.Tag.Get(p.Config.TagName + mapValueTagSuffix))
+	}
+	return field
+}
+
+// resetPlans clear the cached tags and plans, the registered functions are resolved again
+func (p *Pagser) resetPlans() {
+	p.mapTags.Range(func(key, value interface{}) bool {
+		p.mapTags.Delete(key)
+		return true
+	})
+	p.plans.Range(func(key, value interface{}) bool {
+		p.plans.Delete(key)
+		return true
+	})
+}
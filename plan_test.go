@@ -0,0 +1,135 @@
+package pagser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+type BenchmarkItem struct {
+	ID    int      `pagser:"->attr(data-id)"`
+	Name  string   `pagser:"h2 a->text()"`
+	Url   string   `pagser:"h2 a->attr(href)"`
+	Price float64  `pagser:".price->ParsePrice()"`
+	Tags  []string `pagser:".tags li->eachText()"`
+}
+
+func (item BenchmarkItem) ParsePrice(node *goquery.Selection, args ...string) (out interface{}, err error) {
+	return strings.TrimPrefix(strings.TrimSpace(node.Text()), "$"), nil
+}
+
+type BenchmarkData struct {
+	Title string          `pagser:"title"`
+	Items []BenchmarkItem `pagser:".items .item"`
+}
+
+func benchmarkDocument(b *testing.B) *goquery.Document {
+	html := strings.Builder{}
+	html.WriteString("<html><head><title>Benchmark</title></head><body><div class='items'>")
+	for i := 0; i < 50; i++ {
+		fmt.Fprintf(&html, `<div class="item" data-id="%v"><h2><a href="/item/%v">Item %v</a></h2>`+
+			`<span class="price">$%v.99</span><ul class="tags"><li>a</li><li>b</li></ul></div>`, i, i, i, i)
+	}
+	html.WriteString("</div></body></html>")
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html.String()))
+	if err != nil {
+		b.Fatal(err)
+	}
+	return doc
+}
+
+func BenchmarkParseSelection(b *testing.B) {
+	doc := benchmarkDocument(b)
+	p := New()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var data BenchmarkData
+		if err := p.ParseDocument(&data, doc); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseSelectionNoPlanCache(b *testing.B) {
+	doc := benchmarkDocument(b)
+	p := New()
+	//compile the plans of struct and items for each parse, like before plans are cached
+	p.noPlanCache = true
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var data BenchmarkData
+		if err := p.ParseDocument(&data, doc); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseSelectionCold(b *testing.B) {
+	doc := benchmarkDocument(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		//compile tags and plans for each parse
+		p := New()
+		var data BenchmarkData
+		if err := p.ParseDocument(&data, doc); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseSelectionParallel(b *testing.B) {
+	doc := benchmarkDocument(b)
+	p := New()
+	p.Config.Parallelism = 4
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var data BenchmarkData
+		if err := p.ParseDocument(&data, doc); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestPlanRegisterFunc(t *testing.T) {
+	p := New()
+	var data struct {
+		Title string `pagser:"title->myTitle()"`
+	}
+	if err := p.Parse(&data, rawPagserHtml); err == nil {
+		t.Fatal("not registered func want error")
+	}
+	p.RegisterFunc("myTitle", func(node *goquery.Selection, args ...string) (out interface{}, err error) {
+		return "my " + node.Text(), nil
+	})
+	if err := p.Parse(&data, rawPagserHtml); err != nil || data.Title != "my Pagser Example" {
+		t.Errorf("registered func want resolved, but got %v, %v", data.Title, err)
+	}
+}
+
+func TestPlanConfigChanged(t *testing.T) {
+	p := New()
+	var data struct {
+		Title string `pagser:"title->text()" css:"h1=>text()"`
+	}
+	html := "<html><head><title>Title</title></head><body><h1>Heading</h1></body></html>"
+	if err := p.Parse(&data, html); err != nil {
+		t.Fatal(err)
+	}
+	if data.Title != "Title" {
+		t.Fatalf("title want `Title`, but got `%v`", data.Title)
+	}
+	p.Config.TagName = "css"
+	p.Config.FuncSymbol = "=>"
+	if err := p.Parse(&data, html); err != nil {
+		t.Fatal(err)
+	}
+	if data.Title != "Heading" {
+		t.Errorf("title want `Heading` after config changed, but got `%v`", data.Title)
+	}
+}
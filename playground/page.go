@@ -0,0 +1,136 @@
+package playground
+
+// pageHtml the playground page, it posts the inputs to `evaluate` on change and renders the response
+const pageHtml = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Pagser Playground</title>
+<style>
+body{margin:0;font:14px/1.4 -apple-system,"Segoe UI",Helvetica,Arial,sans-serif;color:#24292f}
+header{padding:8px 16px;background:#24292f;color:#fff;font-weight:600}
+main{display:grid;grid-template-columns:1fr 1fr;gap:12px;padding:12px}
+label{display:block;margin:8px 0 4px;font-weight:600}
+textarea,input,select{box-sizing:border-box;width:100%;font:13px/1.4 ui-monospace,Menlo,Consolas,monospace}
+textarea{height:180px}
+iframe{width:100%;height:320px;border:1px solid #d0d7de}
+pre{margin:0;padding:8px;max-height:320px;overflow:auto;background:#f6f8fa;white-space:pre-wrap;word-break:break-all}
+table{width:100%;border-collapse:collapse}
+td,th{padding:4px;border:1px solid #d0d7de;vertical-align:top;text-align:left}
+td pre{max-height:160px}
+.error{color:#cf222e}
+</style>
+</head>
+<body>
+<header>Pagser Playground</header>
+<main>
+<div>
+<label for="html">HTML</label>
+<textarea id="html"><html><body><ul><li><a href="/a">A</a></li><li><a href="/b">B</a></li></ul></body></html></textarea>
+<label for="mode">Mode</label>
+<select id="mode"><option value="expr">Tag expression</option><option value="rules">Rules or struct fields</option></select>
+<div id="exprBox"><label for="expr">Tag expression</label><input id="expr" value="li a->eachAttr(href)"></div>
+<div id="rulesBox" hidden><label for="rules">Rules (JSON, YAML or struct fields with pagser tags)</label>
+<textarea id="rules">Title string   ` + "`" + `pagser:"title"` + "`" + `
+Links []string ` + "`" + `pagser:"li a->absHref()"` + "`" + `</textarea></div>
+<label for="baseUrl">Base URL</label>
+<input id="baseUrl" placeholder="https://example.com/">
+<label>Matched nodes</label>
+<iframe id="preview" sandbox></iframe>
+</div>
+<div>
+<label>Errors</label>
+<pre id="errors"></pre>
+<label>Stages</label>
+<div id="stages"></div>
+<label>Result</label>
+<pre id="result"></pre>
+</div>
+</main>
+<script>
+(function () {
+	var $ = function (id) { return document.getElementById(id); };
+	var timer = null, seq = 0;
+
+	function text(value) {
+		return typeof value === "string" ? value : JSON.stringify(value, null, 2);
+	}
+
+	function cell(row, value, className) {
+		var td = row.insertCell(), pre = document.createElement("pre");
+		pre.textContent = value;
+		if (className) pre.className = className;
+		td.appendChild(pre);
+	}
+
+	function renderStages(name, eval) {
+		var table = document.createElement("table"), head = table.createTHead().insertRow();
+		["Alternative", "Matched", "Stage", "Output"].forEach(function (title) {
+			var th = document.createElement("th");
+			th.textContent = title;
+			head.appendChild(th);
+		});
+		eval.alternatives.forEach(function (alt) {
+			var row = table.insertRow();
+			cell(row, (name ? name + ": " : "") + alt.selector);
+			cell(row, String(alt.matched));
+			cell(row, "nodes");
+			cell(row, alt.nodes.join("\n"));
+			alt.stages.forEach(function (stage) {
+				row = table.insertRow();
+				cell(row, "");
+				cell(row, "");
+				cell(row, stage.func);
+				cell(row, stage.error || text(stage.output), stage.error ? "error" : "");
+			});
+		});
+		return table;
+	}
+
+	function render(resp) {
+		$("preview").srcdoc = resp.html;
+		$("errors").textContent = (resp.errors || []).join("\n");
+		$("result").textContent = JSON.stringify(resp.result, null, 2);
+		var stages = $("stages");
+		stages.innerHTML = "";
+		if (resp.expr) stages.appendChild(renderStages("", resp.expr));
+		Object.keys(resp.fields || {}).sort().forEach(function (name) {
+			stages.appendChild(renderStages(name, resp.fields[name]));
+		});
+	}
+
+	function evaluate() {
+		var rulesMode = $("mode").value === "rules", current = ++seq;
+		var req = {
+			html: $("html").value,
+			expr: rulesMode ? "" : $("expr").value,
+			rules: rulesMode ? $("rules").value : "",
+			baseUrl: $("baseUrl").value
+		};
+		fetch("evaluate", {method: "POST", headers: {"Content-Type": "application/json"}, body: JSON.stringify(req)})
+			.then(function (res) {
+				return res.ok ? res.json() : res.text().then(function (msg) { throw new Error(msg); });
+			})
+			.then(function (resp) { if (current === seq) render(resp); })
+			.catch(function (err) { if (current === seq) $("errors").textContent = err.message; });
+	}
+
+	function schedule() {
+		clearTimeout(timer);
+		timer = setTimeout(evaluate, 300);
+	}
+
+	$("mode").addEventListener("change", function () {
+		$("exprBox").hidden = this.value !== "expr";
+		$("rulesBox").hidden = this.value !== "rules";
+		schedule();
+	});
+	["html", "expr", "rules", "baseUrl"].forEach(function (id) {
+		$(id).addEventListener("input", schedule);
+	});
+	evaluate();
+})();
+</script>
+</body>
+</html>
+`
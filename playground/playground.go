@@ -0,0 +1,299 @@
+// Package playground provides an http.Handler to try selectors and rules on pasted html,
+// it shows the matched nodes highlighted, the output of each function stage and the final json.
+//
+//	http.Handle("/playground/", http.StripPrefix("/playground", playground.New(pagser.New())))
+//
+// The evaluation uses the same tag parser and functions as struct parsing, including the registered functions,
+// and the pasted structs are parsed by reflection like Go structs. Each request uses a clone of Pagser,
+// so the user input is not cached.
+package playground
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/foolin/pagser"
+)
+
+// matchAttr the attribute of highlighted nodes, the value is the alternative index or rule name
+const matchAttr = "data-pagser-match"
+
+// highlightStyle the style of highlighted nodes
+const highlightStyle = `<style>[data-pagser-match]{outline:2px solid #e5534b;background:rgba(255,214,0,.35)}</style>`
+
+// Config configuration
+type Config struct {
+	MaxBodySize int64 //maximum request body size, default is `5MB`
+	MaxNodes    int   //maximum matched nodes of each alternative in response, default is `100`
+}
+
+// DefaultConfig the default Config
+//	Config{
+//		MaxBodySize: 5 << 20,
+//		MaxNodes:    100,
+//	}
+func DefaultConfig() Config {
+	return Config{
+		MaxBodySize: 5 << 20,
+		MaxNodes:    100,
+	}
+}
+
+// Request the evaluate request, Rules is used if it is not empty, otherwise Expr
+type Request struct {
+	HTML    string `json:"html"`    //html document
+	Expr    string `json:"expr"`    //tag expression, eg: `.item a->attr(href)`
+	Rules   string `json:"rules"`   //JSON or YAML rules, or Go struct types or fields with pagser tags, see ParseStruct
+	BaseURL string `json:"baseUrl"` //base url of absHref(), absSrc() and absAttr(name)
+}
+
+// Response the evaluate response
+type Response struct {
+	Expr   *Evaluation            `json:"expr,omitempty"`   //evaluation of expression
+	Fields map[string]*Evaluation `json:"fields,omitempty"` //evaluation of each top level rule
+	Result interface{}            `json:"result"`           //final value of expression, or the parsed map of rules
+	Errors []string               `json:"errors,omitempty"` //parse errors
+	HTML   string                 `json:"html"`             //document with matched nodes highlighted
+}
+
+// Evaluation the evaluation of tag expression
+type Evaluation struct {
+	Tag          *pagser.TagInfo `json:"tag,omitempty"`
+	Alternatives []*Alternative  `json:"alternatives"`
+	Matched      int             `json:"matched"`
+	Value        interface{}     `json:"value"`
+	Error        string          `json:"error,omitempty"`
+}
+
+// Alternative the evaluation of the tag or a fallback alternative
+type Alternative struct {
+	Selector string   `json:"selector"`
+	Matched  int      `json:"matched"`
+	Nodes    []string `json:"nodes"` //outer html of matched nodes, at most Config.MaxNodes
+	Stages   []*Stage `json:"stages"`
+}
+
+// Stage the output of a function
+type Stage struct {
+	Func   string      `json:"func"`
+	Output interface{} `json:"output"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// Handler serves the playground page on `/` and the evaluate api on `/evaluate`
+type Handler struct {
+	Config Config
+	pagser *pagser.Pagser
+	mux    *http.ServeMux
+}
+
+// New create handler with default config
+func New(p *pagser.Pagser) *Handler {
+	h, _ := NewWithConfig(p, DefaultConfig())
+	return h
+}
+
+// NewWithConfig create handler with Config and error
+func NewWithConfig(p *pagser.Pagser, cfg Config) (*Handler, error) {
+	if p == nil {
+		return nil, errors.New("pagser must not nil")
+	}
+	if cfg.MaxBodySize <= 0 {
+		return nil, errors.New("MaxBodySize must be greater than 0")
+	}
+	h := &Handler{Config: cfg, pagser: p, mux: http.NewServeMux()}
+	h.mux.HandleFunc("/", h.servePage)
+	h.mux.HandleFunc("/evaluate", h.serveEvaluate)
+	return h, nil
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) servePage(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, pageHtml)
+}
+
+func (h *Handler) serveEvaluate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req Request
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, h.Config.MaxBodySize)).Decode(&req); err != nil {
+		http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp, err := h.Evaluate(r.Context(), &req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(resp)
+}
+
+// Evaluate the expression or rules of request, the errors of expression and rules are in response,
+// the returned error is only for invalid request.
+func (h *Handler) Evaluate(ctx context.Context, req *Request) (*Response, error) {
+	if strings.TrimSpace(req.Expr) == "" && strings.TrimSpace(req.Rules) == "" {
+		return nil, errors.New("expr or rules is required")
+	}
+	if req.BaseURL != "" {
+		u, err := url.Parse(req.BaseURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base url: %v", err)
+		}
+		ctx = pagser.WithBaseURL(ctx, u)
+	}
+	doc, err := pagser.NewDocument(strings.NewReader(req.HTML), "text/html; charset=utf-8")
+	if err != nil {
+		return nil, err
+	}
+
+	//the caches of cloned pagser are dropped after request, the expressions and structs are user input
+	p := h.pagser.Clone()
+	resp := &Response{}
+	var highlights []highlight
+	switch {
+	case strings.TrimSpace(req.Rules) == "":
+		resp.Expr, highlights = h.evaluate(ctx, p, doc.Selection, req.Expr, "")
+		resp.Result = resp.Expr.Value
+		if resp.Expr.Error != "" {
+			resp.Errors = append(resp.Errors, resp.Expr.Error)
+		}
+	case strings.Contains(req.Rules, p.Config.TagName+`:"`):
+		typ, err := ParseStruct(req.Rules)
+		if err != nil {
+			return nil, err
+		}
+		resp.Fields = make(map[string]*Evaluation, typ.NumField())
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if expr, ok := field.Tag.Lookup(p.Config.TagName); ok && expr != "-" {
+				var nodes []highlight
+				resp.Fields[field.Name], nodes = h.evaluate(ctx, p, doc.Selection, expr, field.Name)
+				highlights = append(highlights, nodes...)
+			}
+		}
+		value := reflect.New(typ)
+		err = p.ParseSelectionContext(ctx, value.Interface(), doc.Selection)
+		resp.Result = pagser.OutputJson(value.Interface())
+		resp.Errors = errorList(err)
+	default:
+		rules, err := pagser.LoadRules([]byte(req.Rules))
+		if err != nil {
+			return nil, err
+		}
+		resp.Fields = make(map[string]*Evaluation, len(rules))
+		for name, rule := range rules {
+			if rule == nil {
+				continue
+			}
+			var nodes []highlight
+			resp.Fields[name], nodes = h.evaluate(ctx, p, doc.Selection, rule.Selector, name)
+			highlights = append(highlights, nodes...)
+		}
+		result, err := p.ParseSelectionWithRulesContext(ctx, doc.Selection, rules)
+		resp.Result = pagser.OutputJson(result)
+		resp.Errors = errorList(err)
+	}
+
+	//mark nodes after evaluation, the marks must not be in outputs
+	for _, hl := range highlights {
+		hl.nodes.SetAttr(matchAttr, hl.value)
+	}
+	doc.Find("head").AppendHtml(highlightStyle)
+	resp.HTML, _ = doc.Html()
+	return resp, nil
+}
+
+// highlight the nodes to mark with value
+type highlight struct {
+	nodes *goquery.Selection
+	value string
+}
+
+// evaluate the expression, the matched nodes are marked with name, or the alternative index if name is empty
+func (h *Handler) evaluate(ctx context.Context, p *pagser.Pagser, selection *goquery.Selection, expr string, name string) (*Evaluation, []highlight) {
+	eval, err := p.EvaluateContext(ctx, selection, expr)
+	result := &Evaluation{Alternatives: []*Alternative{}}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	if eval == nil {
+		return result, nil
+	}
+	result.Tag = eval.Tag
+	result.Matched = eval.Matched
+	result.Value = pagser.OutputJson(eval.Value())
+	var highlights []highlight
+	for i, alt := range eval.Alternatives {
+		value := name
+		if value == "" {
+			value = fmt.Sprint(i)
+		}
+		highlights = append(highlights, highlight{nodes: alt.Nodes, value: value})
+		item := &Alternative{
+			Selector: alt.Selector,
+			Matched:  alt.Nodes.Size(),
+			Nodes:    outerHtmlList(alt.Nodes, h.Config.MaxNodes),
+			Stages:   make([]*Stage, len(alt.Stages)),
+		}
+		for j, stage := range alt.Stages {
+			item.Stages[j] = &Stage{Func: stage.Func, Output: pagser.OutputJson(stage.Output)}
+			if stage.Err != nil {
+				item.Stages[j].Error = stage.Err.Error()
+			}
+		}
+		result.Alternatives = append(result.Alternatives, item)
+	}
+	return result, highlights
+}
+
+// outerHtmlList returns the outer html of first max nodes
+func outerHtmlList(sel *goquery.Selection, max int) []string {
+	list := make([]string, 0, sel.Size())
+	sel.EachWithBreak(func(i int, node *goquery.Selection) bool {
+		if max > 0 && i >= max {
+			return false
+		}
+		html, _ := goquery.OuterHtml(node)
+		list = append(list, html)
+		return true
+	})
+	return list
+}
+
+// errorList returns the messages of error, or each field error of ParseErrors
+func errorList(err error) []string {
+	if err == nil {
+		return nil
+	}
+	var errs pagser.ParseErrors
+	if !errors.As(err, &errs) {
+		return []string{err.Error()}
+	}
+	list := make([]string, len(errs))
+	for i, e := range errs {
+		list[i] = e.Error()
+	}
+	return list
+}
@@ -0,0 +1,158 @@
+package playground
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/foolin/pagser"
+)
+
+const rawHtml = `<html><head><title>Playground</title></head>
+<body><ul><li><a href="/a">A</a></li><li><a href="b">B</a></li></ul></body></html>`
+
+func postEvaluate(t *testing.T, server *httptest.Server, req *Request) (*Response, int) {
+	data, _ := json.Marshal(req)
+	res, err := http.Post(server.URL+"/evaluate", "application/json", strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, res.StatusCode
+	}
+	var resp Response
+	if err = json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	return &resp, res.StatusCode
+}
+
+func TestEvaluateExpr(t *testing.T) {
+	server := httptest.NewServer(New(pagser.New()))
+	defer server.Close()
+
+	resp, _ := postEvaluate(t, server, &Request{HTML: rawHtml, Expr: ".missing || li a->absHref()", BaseURL: "https://example.com/list/"})
+	if resp == nil || resp.Expr == nil || len(resp.Expr.Alternatives) != 2 {
+		t.Fatalf("want 2 alternatives, but got %v", resp)
+	}
+	alt := resp.Expr.Alternatives[1]
+	if alt.Selector != "li a" || alt.Matched != 2 || alt.Nodes[1] != `<a href="b">B</a>` {
+		t.Fatalf("unexpected alternative: %#v", alt)
+	}
+	if len(alt.Stages) != 1 || alt.Stages[0].Func != "absHref()" || alt.Stages[0].Output != "https://example.com/a" {
+		t.Fatalf("unexpected stages: %#v", alt.Stages)
+	}
+	if resp.Result != "https://example.com/a" || len(resp.Errors) != 0 {
+		t.Fatalf("unexpected result %v, errors %v", resp.Result, resp.Errors)
+	}
+	if !strings.Contains(resp.HTML, `<a href="/a" data-pagser-match="1">A</a>`) || strings.Contains(resp.HTML, `<title data-pagser-match`) {
+		t.Fatalf("unexpected highlighted html: %v", resp.HTML)
+	}
+
+	//selection output and failed stage
+	resp, _ = postEvaluate(t, server, &Request{HTML: rawHtml, Expr: "li->nope()"})
+	if len(resp.Errors) != 1 || resp.Expr.Alternatives[0].Stages[0].Error == "" {
+		t.Fatalf("want error of nope(), but got %v", resp.Errors)
+	}
+	resp, _ = postEvaluate(t, server, &Request{HTML: rawHtml, Expr: "li"})
+	if result, ok := resp.Result.(string); !ok || result != "AB" {
+		t.Fatalf("want text AB, but got %v", resp.Result)
+	}
+}
+
+func TestEvaluateRules(t *testing.T) {
+	server := httptest.NewServer(New(pagser.New()))
+	defer server.Close()
+
+	tests := []string{
+		"title: title\nlinks:\n  selector: li a->eachAttr(href)\n  list: true\n",
+		"type Page struct {\n\tTitle string `pagser:\"title\"`\n\tLinks []string `json:\"links\" pagser:\"li a->eachAttr(href)\"`\n\tSkip int\n}\n",
+	}
+	for _, rules := range tests {
+		resp, code := postEvaluate(t, server, &Request{HTML: rawHtml, Rules: rules})
+		if code != http.StatusOK {
+			t.Fatalf("%v: unexpected status %v", rules, code)
+		}
+		data, _ := json.Marshal(resp.Result)
+		if !strings.Contains(strings.ToLower(string(data)), `"title":"playground"`) || len(resp.Fields) != 2 {
+			t.Fatalf("%v: unexpected result %s", rules, data)
+		}
+		if !strings.Contains(resp.HTML, `<title data-pagser-match="`) {
+			t.Fatalf("%v: title is not highlighted", rules)
+		}
+	}
+}
+
+func TestEvaluateStruct(t *testing.T) {
+	server := httptest.NewServer(New(pagser.New()))
+	defer server.Close()
+
+	rules := "type Page struct {\n" +
+		"\tTitle string `json:\"title\" pagser:\"title\"`\n" +
+		"\tLinks []Link `json:\"links\" pagser:\"li\"`\n" +
+		"\tHrefs map[string]string `json:\"hrefs\" pagser:\"li a\" pagser_value:\"->attr(href)\"`\n" +
+		"\tCount int `json:\"count\" pagser:\"li->size()\"`\n" +
+		"\tStars int `json:\"stars\" pagser:\".stars\" pagser_opts:\"default=3\"`\n" +
+		"}\n" +
+		"type Link struct {\n" +
+		"\tName string `json:\"name\" pagser:\"a\"`\n" +
+		"}\n"
+	resp, code := postEvaluate(t, server, &Request{HTML: rawHtml, Rules: rules})
+	if code != http.StatusOK {
+		t.Fatalf("unexpected status %v", code)
+	}
+	data, _ := json.Marshal(resp.Result)
+	want := `{"count":2,"hrefs":{"A":"/a","B":"b"},"links":[{"name":"A"},{"name":"B"}],"stars":3,"title":"Playground"}`
+	if string(data) != want || len(resp.Errors) != 0 {
+		t.Fatalf("want %v, but got %s with errors %v", want, data, resp.Errors)
+	}
+	if len(resp.Fields) != 5 || resp.Fields["Links"].Matched != 2 {
+		t.Fatalf("unexpected fields: %v", resp.Fields)
+	}
+
+	for _, rules := range []string{
+		"title string `pagser:\"title\"`",
+		"Title chan int `pagser:\"title\"`",
+		"Title [2]string `pagser:\"title\"`",
+		"Item Item `pagser:\"li\"`\n}\ntype Item struct {\n\tItem *Item `pagser:\"li\"`",
+		"Title os.File `pagser:\"title\"`",
+	} {
+		if _, code := postEvaluate(t, server, &Request{HTML: rawHtml, Rules: rules}); code != http.StatusBadRequest {
+			t.Errorf("%v want 400, but got %v", rules, code)
+		}
+	}
+}
+
+func TestHandlerErrors(t *testing.T) {
+	server := httptest.NewServer(New(pagser.New()))
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK || !strings.HasPrefix(res.Header.Get("Content-Type"), "text/html") {
+		t.Fatalf("unexpected page response: %v", res.Status)
+	}
+	if res, err = http.Get(server.URL + "/evaluate"); err != nil || res.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("want 405, but got %v", res.Status)
+	}
+	res.Body.Close()
+
+	for _, req := range []*Request{
+		{HTML: rawHtml},
+		{HTML: rawHtml, Rules: "Title string `pagser:\"title\"`\nnot a field = 1"},
+		{HTML: rawHtml, Expr: "a", BaseURL: ":bad"},
+	} {
+		if _, code := postEvaluate(t, server, req); code != http.StatusBadRequest {
+			t.Errorf("%#v want 400, but got %v", req, code)
+		}
+	}
+	if _, err := NewWithConfig(nil, DefaultConfig()); err == nil {
+		t.Error("nil pagser must return error")
+	}
+}
@@ -0,0 +1,201 @@
+package playground
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// basicTypes the predeclared types of struct fields
+var basicTypes = map[string]reflect.Type{
+	"bool":    reflect.TypeOf(false),
+	"string":  reflect.TypeOf(""),
+	"int":     reflect.TypeOf(int(0)),
+	"int8":    reflect.TypeOf(int8(0)),
+	"int16":   reflect.TypeOf(int16(0)),
+	"int32":   reflect.TypeOf(int32(0)),
+	"int64":   reflect.TypeOf(int64(0)),
+	"uint":    reflect.TypeOf(uint(0)),
+	"uint8":   reflect.TypeOf(uint8(0)),
+	"uint16":  reflect.TypeOf(uint16(0)),
+	"uint32":  reflect.TypeOf(uint32(0)),
+	"uint64":  reflect.TypeOf(uint64(0)),
+	"float32": reflect.TypeOf(float32(0)),
+	"float64": reflect.TypeOf(float64(0)),
+	"byte":    reflect.TypeOf(byte(0)),
+	"rune":    reflect.TypeOf(rune(0)),
+	"any":     reflect.TypeOf((*interface{})(nil)).Elem(),
+}
+
+// packageTypes the types of packages can be used by struct fields
+var packageTypes = map[string]reflect.Type{
+	"time.Time": reflect.TypeOf(time.Time{}),
+	"url.URL":   reflect.TypeOf(url.URL{}),
+}
+
+// ParseStruct parse the Go source of struct types, or the fields of a struct, one field per line.
+// The first struct type is returned, it is built by reflect.StructOf, so it is parsed by the same plan as Go structs,
+// with the field options, map fields, nested structs and type conversion.
+// The field types can be the predeclared types, time.Time, url.URL, the struct types of source,
+// and the pointers, slices and maps of them. The methods and embedded fields are not supported.
+//	type Page struct {
+//		Title string   `pagser:"title"`
+//		Links []string `pagser:"a->eachAttr(href)"`
+//	}
+func ParseStruct(source string) (reflect.Type, error) {
+	if !strings.HasPrefix(strings.TrimSpace(source), "type ") {
+		source = "type Page struct {" + source + "\n}"
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", "package playground; "+source, 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid struct: %v", err)
+	}
+	b := &structBuilder{specs: make(map[string]*ast.TypeSpec), types: make(map[string]reflect.Type), building: make(map[string]bool)}
+	var first *ast.TypeSpec
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			return nil, errors.New("only struct types are supported")
+		}
+		for _, spec := range genDecl.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			b.specs[typeSpec.Name.Name] = typeSpec
+			if first == nil {
+				first = typeSpec
+			}
+		}
+	}
+	if first == nil {
+		return nil, errors.New("no struct type")
+	}
+	typ, err := b.namedType(first.Name.Name)
+	if err != nil {
+		return nil, err
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("type %v must be struct", first.Name.Name)
+	}
+	return typ, nil
+}
+
+// structBuilder builds the reflect types of type specs
+type structBuilder struct {
+	specs    map[string]*ast.TypeSpec //type name => spec
+	types    map[string]reflect.Type  //type name => built type
+	building map[string]bool          //type names are building, to check recursive types
+}
+
+// namedType returns the type of type spec in source
+func (b *structBuilder) namedType(name string) (reflect.Type, error) {
+	if typ, ok := b.types[name]; ok {
+		return typ, nil
+	}
+	spec, ok := b.specs[name]
+	if !ok {
+		return nil, fmt.Errorf("type %v is not supported", name)
+	}
+	if b.building[name] {
+		return nil, fmt.Errorf("recursive type %v is not supported", name)
+	}
+	b.building[name] = true
+	typ, err := b.typeOf(spec.Type)
+	if err != nil {
+		return nil, err
+	}
+	b.types[name] = typ
+	return typ, nil
+}
+
+// typeOf returns the type of type expression
+func (b *structBuilder) typeOf(expr ast.Expr) (reflect.Type, error) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if typ, ok := basicTypes[t.Name]; ok {
+			return typ, nil
+		}
+		return b.namedType(t.Name)
+	case *ast.SelectorExpr:
+		name := types.ExprString(t)
+		if typ, ok := packageTypes[name]; ok {
+			return typ, nil
+		}
+		return nil, fmt.Errorf("type %v is not supported", name)
+	case *ast.StarExpr:
+		elem, err := b.typeOf(t.X)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.PtrTo(elem), nil
+	case *ast.ArrayType:
+		if t.Len != nil {
+			return nil, errors.New("array types are not supported, use slice")
+		}
+		elem, err := b.typeOf(t.Elt)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.SliceOf(elem), nil
+	case *ast.MapType:
+		key, err := b.typeOf(t.Key)
+		if err != nil {
+			return nil, err
+		}
+		if key.Kind() == reflect.Struct || key.Kind() == reflect.Slice || key.Kind() == reflect.Map {
+			return nil, fmt.Errorf("map key type %v is not supported", key)
+		}
+		value, err := b.typeOf(t.Value)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.MapOf(key, value), nil
+	case *ast.InterfaceType:
+		if t.Methods.NumFields() > 0 {
+			return nil, errors.New("interface types with methods are not supported")
+		}
+		return basicTypes["any"], nil
+	case *ast.StructType:
+		return b.structOf(t)
+	}
+	return nil, fmt.Errorf("type %v is not supported", types.ExprString(expr))
+}
+
+// structOf returns the struct type of fields, the fields must be exported
+func (b *structBuilder) structOf(structType *ast.StructType) (reflect.Type, error) {
+	fields := make([]reflect.StructField, 0, structType.Fields.NumFields())
+	names := make(map[string]bool)
+	for _, field := range structType.Fields.List {
+		if len(field.Names) == 0 {
+			return nil, errors.New("embedded fields are not supported")
+		}
+		typ, err := b.typeOf(field.Type)
+		if err != nil {
+			return nil, err
+		}
+		var tag string
+		if field.Tag != nil {
+			if tag, err = strconv.Unquote(field.Tag.Value); err != nil {
+				return nil, fmt.Errorf("invalid tag %v: %v", field.Tag.Value, err)
+			}
+		}
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				return nil, fmt.Errorf("field %v must be exported", name.Name)
+			}
+			if names[name.Name] {
+				return nil, fmt.Errorf("duplicate field %v", name.Name)
+			}
+			names[name.Name] = true
+			fields = append(fields, reflect.StructField{Name: name.Name, Type: typ, Tag: reflect.StructTag(tag)})
+		}
+	}
+	return reflect.StructOf(fields), nil
+}
@@ -0,0 +1,172 @@
+package pagser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"gopkg.in/yaml.v3"
+)
+
+// Rule the declarative parse rule of a value, the selector has the same grammar as struct tag.
+// A rule can be a string of selector in JSON or YAML:
+//	title: h1->text()
+//	keywords: meta[name='keywords']->attrSplit(content)
+//	items:
+//	  selector: .item
+//	  list: true
+//	  fields:
+//	    name: a->text()
+//	    url: a->attr(href)
+type Rule struct {
+	Selector string `json:"selector" yaml:"selector"`                 //selector and functions, eg: `a->attr(href)`
+	List     bool   `json:"list,omitempty" yaml:"list,omitempty"`     //each node matched by selector is an item, the function output must be a slice
+	Fields   Rules  `json:"fields,omitempty" yaml:"fields,omitempty"` //nested rules relative to the node
+}
+
+// Rules the rules of values, name => rule
+type Rules map[string]*Rule
+
+// rule is Rule without custom unmarshal methods
+type rule Rule
+
+// UnmarshalJSON implements json.Unmarshaler, the rule can be a selector string
+func (r *Rule) UnmarshalJSON(data []byte) error {
+	if strings.HasPrefix(strings.TrimSpace(string(data)), `"`) {
+		return json.Unmarshal(data, &r.Selector)
+	}
+	return json.Unmarshal(data, (*rule)(r))
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, the rule can be a selector string
+func (r *Rule) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&r.Selector)
+	}
+	return value.Decode((*rule)(r))
+}
+
+// LoadRules load rules from JSON or YAML data
+func LoadRules(data []byte) (Rules, error) {
+	var rules Rules
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("invalid rules: %v", err)
+	}
+	return rules, nil
+}
+
+// LoadRulesFile load rules from JSON or YAML file
+func LoadRulesFile(filename string) (Rules, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return LoadRules(data)
+}
+
+// ParseWithRules parse document to map by rules, the values are string if the selector has no functions,
+// []string if the rule is list, map[string]interface{} and []map[string]interface{} if the rule has fields,
+// or the output of the last function.
+func (p *Pagser) ParseWithRules(document *goquery.Document, rules Rules) (map[string]interface{}, error) {
+	ctx := context.Background()
+	if document.Url != nil {
+		ctx = WithBaseURL(ctx, document.Url)
+	}
+	return p.ParseSelectionWithRulesContext(ctx, document.Selection, rules)
+}
+
+// ParseSelectionWithRules parse selection to map by rules
+func (p *Pagser) ParseSelectionWithRules(selection *goquery.Selection, rules Rules) (map[string]interface{}, error) {
+	return p.ParseSelectionWithRulesContext(context.Background(), selection, rules)
+}
+
+// ParseSelectionWithRulesContext parse selection to map by rules, the parsing stops with the context error if ctx is done
+func (p *Pagser) ParseSelectionWithRulesContext(ctx context.Context, selection *goquery.Selection, rules Rules) (map[string]interface{}, error) {
+	state := p.newParseState(withDocumentBaseURL(ctx, selection))
+	result, err := p.parseRules(state, rules, selection, "")
+	if err != nil {
+		return result, err
+	}
+	if len(state.errs) > 0 {
+		return result, state.errs
+	}
+	return result, nil
+}
+
+// parseRules parse selection by rules in name order, path is the path of nested rules, eg: `items[3]`
+func (p *Pagser) parseRules(state *parseState, rules Rules, selection *goquery.Selection, path string) (map[string]interface{}, error) {
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make(map[string]interface{}, len(rules))
+	for _, name := range names {
+		if err := state.ctx.Err(); err != nil {
+			return result, err
+		}
+		rulePath := joinFieldPath(path, name)
+		rule := rules[name]
+		if rule == nil {
+			continue
+		}
+		tag, err := p.getTag(rule.Selector)
+		if err != nil {
+			if err = state.fail(rulePath, &tagTokenizer{Value: rule.Selector}, err); err != nil {
+				return result, err
+			}
+			continue
+		}
+		value, err := p.parseRule(state, rule, tag, selection, rulePath)
+		if err != nil {
+			if err = state.fail(rulePath, tag, err); err != nil {
+				return result, err
+			}
+			continue
+		}
+		result[name] = value
+	}
+	return result, nil
+}
+
+// parseRule parse the value of rule
+func (p *Pagser) parseRule(state *parseState, rule *Rule, tag *tagTokenizer, selection *goquery.Selection, path string) (interface{}, error) {
+	out, _, err := p.execTag(state, reflect.Value{}, nil, tag, selection)
+	if err != nil {
+		return nil, fmt.Errorf("parse func error: %w", err)
+	}
+	node, ok := out.(*goquery.Selection)
+	if !ok {
+		if len(rule.Fields) > 0 {
+			return nil, fmt.Errorf("fields require a selection, but output is %T", out)
+		}
+		if kind := reflect.ValueOf(out).Kind(); rule.List && kind != reflect.Slice && kind != reflect.Array {
+			return nil, fmt.Errorf("list requires a selection or slice, but output is %T, use each functions like eachText()", out)
+		}
+		return out, nil
+	}
+	switch {
+	case rule.List && len(rule.Fields) > 0:
+		items := make([]map[string]interface{}, node.Size())
+		node.EachWithBreak(func(i int, subNode *goquery.Selection) bool {
+			items[i], err = p.parseRules(state, rule.Fields, subNode, indexFieldPath(path, strconv.Itoa(i)))
+			return err == nil
+		})
+		return items, err
+	case len(rule.Fields) > 0:
+		return p.parseRules(state, rule.Fields, node, path)
+	case rule.List:
+		return node.Map(func(i int, subNode *goquery.Selection) string {
+			return strings.TrimSpace(subNode.Text())
+		}), nil
+	default:
+		return strings.TrimSpace(node.Text()), nil
+	}
+}
@@ -0,0 +1,132 @@
+package pagser
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const rawRulesYaml = `
+title: title
+keywords: meta[name='keywords']->attrSplit(content)
+count: .navlink li->size()
+upper: .navlink li->eq(1)->myUpper()
+navs:
+  selector: .navlink li
+  list: true
+  fields:
+    id: ->attrEmpty(id, -1)
+    name: a->text()
+    url: a->attr(href)
+names:
+  selector: .navlink li a
+  list: true
+container:
+  selector: .container
+  fields:
+    first: li->first()->text()
+`
+
+const rawRulesJson = `{
+	"title": "title",
+	"names": {"selector": ".navlink li a", "list": true},
+	"container": {"selector": ".container", "fields": {"first": "li->first()->text()"}}
+}`
+
+func TestParseWithRules(t *testing.T) {
+	p := New()
+	p.RegisterFunc("myUpper", func(node *goquery.Selection, args ...string) (out interface{}, err error) {
+		return strings.ToUpper(node.Text()), nil
+	})
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(rawPagserHtml))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := LoadRules([]byte(rawRulesYaml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := p.ParseWithRules(doc, rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"title":    "Pagser Example",
+		"keywords": []string{"golang", "pagser", "goquery", "html", "page", "parser", "colly"},
+		"count":    4,
+		"upper":    "WEB PAGE",
+		"navs": []map[string]interface{}{
+			{"id": "-1", "name": "Index", "url": "/"},
+			{"id": "2", "name": "Web page", "url": "/list/web"},
+			{"id": "3", "name": "Pc Page", "url": "/list/pc"},
+			{"id": "4", "name": "Mobile Page", "url": "/list/mobile"},
+		},
+		"names":     []string{"Index", "Web page", "Pc Page", "Mobile Page"},
+		"container": map[string]interface{}{"first": "Index"},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("want %v, but got %v", prettyJson(want), prettyJson(result))
+	}
+
+	rules, err = LoadRules([]byte(rawRulesJson))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err = p.ParseWithRules(doc, rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result["title"] != "Pagser Example" || len(result["names"].([]string)) != 4 || result["container"].(map[string]interface{})["first"] != "Index" {
+		t.Errorf("unexpected result: %v", prettyJson(result))
+	}
+}
+
+func TestParseWithRulesErrors(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ContinueOnError = true
+	p, err := NewWithConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(rawPagserHtml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules, err := LoadRules([]byte(`
+title: title
+bad: a->attr(
+navs:
+  selector: .navlink li
+  list: true
+  fields:
+    name: a->notExists()
+texts:
+  selector: .navlink li->text()
+  list: true
+eachTexts:
+  selector: .navlink li->eachText()
+  list: true
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := p.ParseWithRules(doc, rules)
+	var parseErrs ParseErrors
+	if !errors.As(err, &parseErrs) || len(parseErrs) != 6 {
+		t.Fatalf("want 6 ParseErrors, but got %v", err)
+	}
+	if parseErrs[0].Path != "bad" || parseErrs[1].Path != "navs[0].name" || parseErrs[5].Path != "texts" {
+		t.Errorf("unexpected error paths: %v", err)
+	}
+	if result["title"] != "Pagser Example" || len(result["eachTexts"].([]string)) == 0 {
+		t.Errorf("valid rules want parsed, but got %v", prettyJson(result))
+	}
+
+	if _, err = LoadRules([]byte("title: [")); err == nil {
+		t.Error("invalid yaml want error")
+	}
+}
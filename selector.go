@@ -0,0 +1,151 @@
+package pagser
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// maxAncestors the maximum ancestors in the selectors of candidates
+const maxAncestors = 4
+
+// ignoredTags the elements are not searched for selectors
+var ignoredTags = map[string]bool{"script": true, "style": true, "noscript": true, "template": true}
+
+// IsIgnoredTag the element of tag name is not searched for selectors, eg: `script`, `style`
+func IsIgnoredTag(name string) bool {
+	return ignoredTags[name]
+}
+
+// SelectorCandidates returns the css selectors of node relative to root from the simplest,
+// they are made of the stable ids, classes and tags of node and its ancestors, and the last one is the full path of node.
+func SelectorCandidates(root *goquery.Selection, node *goquery.Selection) []string {
+	own := nodeSegments(node)
+	candidates := append([]string{}, own...)
+	for i, ancestor := 0, node.Parent(); i < maxAncestors && ancestor.Length() > 0 && !ancestor.IsSelection(root); i, ancestor = i+1, ancestor.Parent() {
+		if goquery.NodeName(ancestor) == "html" || goquery.NodeName(ancestor) == "body" {
+			break
+		}
+		for _, parent := range nodeSegments(ancestor) {
+			for _, seg := range own {
+				candidates = append(candidates, parent+" "+seg)
+			}
+		}
+		if _, ok := stableID(ancestor); ok {
+			break
+		}
+	}
+	return append(candidates, nodePath(root, node))
+}
+
+// nodeSegments returns the selectors of node itself, eg: `#main`, `div.item.new`, `div.item` and `div`
+func nodeSegments(node *goquery.Selection) []string {
+	var segments []string
+	if id, ok := stableID(node); ok {
+		segments = append(segments, "#"+id)
+	}
+	tag := goquery.NodeName(node)
+	classes := stableClasses(node)
+	if len(classes) > 1 {
+		segments = append(segments, tag+"."+strings.Join(classes, "."))
+	}
+	for _, class := range classes {
+		segments = append(segments, tag+"."+class)
+	}
+	return append(segments, tag)
+}
+
+// nodePath returns the full path of node from the nearest ancestor with id, or from root,
+// eg: `#main > ul:nth-of-type(1) > li:nth-of-type(2)`
+func nodePath(root *goquery.Selection, node *goquery.Selection) string {
+	var path []string
+	for ; node.Length() > 0 && !strings.HasPrefix(goquery.NodeName(node), "#") && !node.IsSelection(root); node = node.Parent() {
+		if id, ok := stableID(node); ok {
+			path = append(path, "#"+id)
+			break
+		}
+		tag := goquery.NodeName(node)
+		index := node.PrevAllFiltered(tag).Length() + 1
+		path = append(path, fmt.Sprintf("%v:nth-of-type(%v)", tag, index))
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return strings.Join(path, " > ")
+}
+
+// stableID returns the id of node if it is stable
+func stableID(node *goquery.Selection) (string, bool) {
+	id, ok := node.Attr("id")
+	return id, ok && isStableName(id)
+}
+
+// stableClasses returns the stable classes of node
+func stableClasses(node *goquery.Selection) []string {
+	var classes []string
+	for _, class := range strings.Fields(node.AttrOr("class", "")) {
+		if isStableName(class) {
+			classes = append(classes, class)
+		}
+	}
+	return classes
+}
+
+// isStableName returns true if the id or class is a plain name, the names with digits are usually generated, eg: `css-1x2y3z`
+func isStableName(name string) bool {
+	if name == "" || len(name) > 32 || name[0] == '-' {
+		return false
+	}
+	for _, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// selectorNameRegexp matches the ids and classes of css selector
+var selectorNameRegexp = regexp.MustCompile(`[#.]([\w-]+)`)
+
+// positionalRegexp matches the positional pseudo classes of css selector
+var positionalRegexp = regexp.MustCompile(`:(nth-|eq\(|gt\(|lt\(|first|last|only)`)
+
+// SelectorScore returns the robustness score of css selector from 0 to 1, the short selectors of stable ids and classes
+// score higher than the positional selectors, the generated names like `css-1x2y3z`, bare tags and long paths.
+// The xpath selectors score 0.5.
+//	SelectorScore("#main h1.title") //0.9
+//	SelectorScore("ul.tags > li:nth-of-type(2)") //0.55
+func SelectorScore(selector string) float64 {
+	selector = strings.TrimSpace(selector)
+	if selector == "" {
+		return 0
+	}
+	if strings.HasPrefix(selector, xpathPrefix) {
+		return 0.5
+	}
+	score := 1.0
+	segments := strings.FieldsFunc(selector, func(r rune) bool {
+		return r == ' ' || r == '>' || r == '+' || r == '~'
+	})
+	score -= 0.1 * float64(len(segments)-1)
+	score -= 0.05 * float64(strings.Count(selector, ">")+strings.Count(selector, "+")+strings.Count(selector, "~"))
+	for _, segment := range segments {
+		if !strings.ContainsAny(segment, "#.[") {
+			score -= 0.05
+		}
+	}
+	if !strings.ContainsAny(selector, "#.[") {
+		//only tags, any new element of the tags is matched
+		score -= 0.3
+	}
+	score -= 0.25 * float64(len(positionalRegexp.FindAllString(selector, -1)))
+	for _, name := range selectorNameRegexp.FindAllStringSubmatch(selector, -1) {
+		if !isStableName(name[1]) {
+			score -= 0.2
+		}
+	}
+	return math.Round(math.Max(0, math.Min(1, score))*100) / 100
+}
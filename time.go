@@ -0,0 +1,168 @@
+package pagser
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	timePtrType = reflect.TypeOf(&time.Time{})
+)
+
+// timeNow current time for relative time, replaced in tests
+var timeNow = time.Now
+
+//3 hours ago
+//in 2 days
+//a minute ago
+var rxRelativeTime = regexp.MustCompile(`^(?i)(in\s+)?(\d+|an?)\s*(second|sec|minute|min|hour|hr|day|week|month|year)s?(\s+ago)?$`)
+
+// toTimeE casts an interface to a time.Time type in location, string value supports
+// layouts of cast.ToTimeE, unix timestamps and relative time like `3 hours ago`.
+func toTimeE(v interface{}, loc *time.Location) (time.Time, error) {
+	switch tv := v.(type) {
+	case time.Time:
+		return tv, nil
+	case *time.Time:
+		if tv == nil {
+			return time.Time{}, fmt.Errorf("unable to cast %#v of type %T to time.Time", v, v)
+		}
+		return *tv, nil
+	case string:
+		return parseTime(tv, "", loc)
+	case []byte:
+		return parseTime(string(tv), "", loc)
+	case int, int32, int64, uint, uint32, uint64, float64:
+		return unixTime(cast.ToInt64(tv), loc), nil
+	default:
+		return cast.ToTimeInDefaultLocationE(v, loc)
+	}
+}
+
+// parseTime parse text to time.Time with layout in location,
+// if layout is empty, try digits date or unix timestamp, relative time and common layouts.
+func parseTime(text string, layout string, loc *time.Location) (time.Time, error) {
+	text = strings.TrimSpace(text)
+	if loc == nil {
+		loc = time.UTC
+	}
+	switch layout {
+	case "":
+	case "unix":
+		sec, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("`%v` is not unix timestamp", text)
+		}
+		return time.Unix(sec, 0).In(loc), nil
+	case "unixMilli":
+		msec, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("`%v` is not unix milli timestamp", text)
+		}
+		return time.UnixMilli(msec).In(loc), nil
+	default:
+		return time.ParseInLocation(layout, text, loc)
+	}
+
+	if text == "" {
+		return time.Time{}, fmt.Errorf("unable to cast empty string to time.Time")
+	}
+	if isDigits(text) {
+		return parseDigitsTime(text, loc)
+	}
+	if t, ok := parseRelativeTime(text, loc); ok {
+		return t, nil
+	}
+	return cast.ToTimeInDefaultLocationE(text, loc)
+}
+
+// digitsLayouts the layouts of short digits, eg: `2024` and `20240115`
+var digitsLayouts = map[int]string{4: "2006", 6: "200601", 8: "20060102"}
+
+// parseDigitsTime parse digits as date of `2006`, `200601` or `20060102`,
+// or unix timestamp in seconds or milliseconds if it has at least 10 digits.
+func parseDigitsTime(text string, loc *time.Location) (time.Time, error) {
+	if layout, ok := digitsLayouts[len(text)]; ok {
+		return time.ParseInLocation(layout, text, loc)
+	}
+	if len(strings.TrimPrefix(text, "-")) < 10 {
+		return time.Time{}, fmt.Errorf("`%v` is not a date or unix timestamp, use unix layout for short timestamps", text)
+	}
+	ts, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("`%v` is not unix timestamp: %v", text, err)
+	}
+	return unixTime(ts, loc), nil
+}
+
+// isDigits the text is digits with optional minus sign
+func isDigits(text string) bool {
+	text = strings.TrimPrefix(text, "-")
+	if text == "" {
+		return false
+	}
+	for _, c := range text {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// unixTime unix timestamp in seconds, or milliseconds if the value is too large for seconds
+func unixTime(ts int64, loc *time.Location) time.Time {
+	//year 5138 in seconds
+	if ts > 1e11 || ts < -1e11 {
+		return time.UnixMilli(ts).In(loc)
+	}
+	return time.Unix(ts, 0).In(loc)
+}
+
+// parseRelativeTime parse relative time like `now`, `yesterday`, `3 hours ago` and `in 2 days`
+func parseRelativeTime(text string, loc *time.Location) (time.Time, bool) {
+	now := timeNow().In(loc)
+	switch strings.ToLower(text) {
+	case "now", "just now":
+		return now, true
+	case "today":
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc), true
+	case "yesterday":
+		return time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, loc), true
+	case "tomorrow":
+		return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, loc), true
+	}
+	matches := rxRelativeTime.FindStringSubmatch(text)
+	if len(matches) == 0 || (matches[1] == "") == (matches[4] == "") {
+		return time.Time{}, false
+	}
+	n := 1
+	if num, err := strconv.Atoi(matches[2]); err == nil {
+		n = num
+	}
+	if matches[4] != "" {
+		n = -n
+	}
+	switch strings.ToLower(matches[3]) {
+	case "second", "sec":
+		return now.Add(time.Duration(n) * time.Second), true
+	case "minute", "min":
+		return now.Add(time.Duration(n) * time.Minute), true
+	case "hour", "hr":
+		return now.Add(time.Duration(n) * time.Hour), true
+	case "day":
+		return now.AddDate(0, 0, n), true
+	case "week":
+		return now.AddDate(0, 0, n*7), true
+	case "month":
+		return now.AddDate(0, n, 0), true
+	default:
+		return now.AddDate(n, 0, 0), true
+	}
+}
@@ -0,0 +1,126 @@
+package pagser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2020, 5, 10, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	tests := []struct {
+		text   string
+		layout string
+		want   time.Time
+	}{
+		{"2020-04-25", "", time.Date(2020, 4, 25, 0, 0, 0, 0, time.UTC)},
+		{"2020-04-25T10:20:30Z", "", time.Date(2020, 4, 25, 10, 20, 30, 0, time.UTC)},
+		{"1588000000", "", time.Unix(1588000000, 0)},
+		{"1588000000123", "", time.UnixMilli(1588000000123)},
+		{"3 hours ago", "", now.Add(-3 * time.Hour)},
+		{"a day ago", "", now.AddDate(0, 0, -1)},
+		{"in 2 weeks", "", now.AddDate(0, 0, 14)},
+		{"yesterday", "", time.Date(2020, 5, 9, 0, 0, 0, 0, time.UTC)},
+		{"just now", "", now},
+		{"25/04/2020", "02/01/2006", time.Date(2020, 4, 25, 0, 0, 0, 0, time.UTC)},
+		{"1588000000", "unix", time.Unix(1588000000, 0)},
+		{"2024", "", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"202401", "", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"20240115", "", time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{"86400", "unix", time.Unix(86400, 0)},
+	}
+	for _, tt := range tests {
+		got, err := parseTime(tt.text, tt.layout, time.UTC)
+		if err != nil {
+			t.Errorf("parseTime(%v, %v) error: %v", tt.text, tt.layout, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseTime(%v, %v) want %v, but got %v", tt.text, tt.layout, tt.want, got)
+		}
+	}
+
+	for _, text := range []string{"", "3 hours", "in 3 hours ago", "not a time", "86400", "20241315"} {
+		if _, err := parseTime(text, "", time.UTC); err == nil {
+			t.Errorf("parseTime(%v) want an error", text)
+		}
+	}
+}
+
+const rawTimeHtml = `
+<html>
+<body>
+	<time class="created" datetime="2020-04-25T10:20:30Z">April 25</time>
+	<span class="updated" data-ts="1588000000">updated</span>
+	<span class="local">25.04.2020 10:20</span>
+	<ul>
+		<li>2020-04-25</li>
+		<li>2020-04-26</li>
+	</ul>
+	<span class="invalid">unknown</span>
+</body>
+</html>
+`
+
+type TimeData struct {
+	Created     time.Time   `pagser:".created->attr(datetime)"`
+	CreatedPtr  *time.Time  `pagser:".created->attr(datetime)"`
+	Updated     time.Time   `pagser:".updated->attr(data-ts)"`
+	Local       time.Time   `pagser:".local->time('02.01.2006 15:04', 'Europe/Berlin')"`
+	Dates       []time.Time `pagser:"ul li"`
+	DatesFunc   []time.Time `pagser:"ul li->eachText()->time('2006-01-02')"`
+	DateText    time.Time   `pagser:"ul li:first-child"`
+	Invalid     time.Time   `pagser:".invalid"`
+	InvalidFunc time.Time   `pagser:".invalid->time('2006-01-02')"`
+}
+
+func TestParseTimeField(t *testing.T) {
+	p := New()
+
+	var data TimeData
+	err := p.Parse(&data, rawTimeHtml)
+	if err != nil {
+		t.Fatal(err)
+	}
+	created := time.Date(2020, 4, 25, 10, 20, 30, 0, time.UTC)
+	if !data.Created.Equal(created) || data.CreatedPtr == nil || !data.CreatedPtr.Equal(created) {
+		t.Errorf("created want %v, but got %v / %v", created, data.Created, data.CreatedPtr)
+	}
+	if !data.Updated.Equal(time.Unix(1588000000, 0)) {
+		t.Errorf("updated want %v, but got %v", time.Unix(1588000000, 0), data.Updated)
+	}
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	if local := time.Date(2020, 4, 25, 10, 20, 0, 0, berlin); !data.Local.Equal(local) {
+		t.Errorf("local want %v, but got %v", local, data.Local)
+	}
+	if len(data.Dates) != 2 || data.Dates[1].Day() != 26 || len(data.DatesFunc) != 2 || data.DatesFunc[1].Day() != 26 {
+		t.Errorf("dates want 2020-04-25 and 2020-04-26, but got %v / %v", data.Dates, data.DatesFunc)
+	}
+	if data.DateText.Day() != 25 {
+		t.Errorf("date text want 2020-04-25, but got %v", data.DateText)
+	}
+	if !data.Invalid.IsZero() || !data.InvalidFunc.IsZero() {
+		t.Errorf("invalid time want zero value, but got %v / %v", data.Invalid, data.InvalidFunc)
+	}
+
+	cfg := DefaultConfig()
+	cfg.CastError = true
+	p, err = NewWithConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var errData struct {
+		Invalid time.Time `pagser:".invalid"`
+	}
+	if err := p.Parse(&errData, rawTimeHtml); err == nil {
+		t.Error("invalid time must return error when CastError is true")
+	}
+	var errFuncData struct {
+		Invalid time.Time `pagser:".invalid->time('2006-01-02')"`
+	}
+	if err := p.Parse(&errFuncData, rawTimeHtml); err == nil {
+		t.Error("invalid time func must return error when CastError is true")
+	}
+}
@@ -5,6 +5,10 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+	"github.com/antchfx/xpath"
 )
 
 type tokenState int
@@ -20,48 +24,157 @@ const (
 //->fn(xxx)
 //->fn('xxx')
 //->fn('xxx\'xxx', 'xxx,xxx')
-var rxFunc = regexp.MustCompile("^\\s*([a-zA-Z]+)\\s*(\\(([^\\)]*)\\))?\\s*$")
+var rxFunc = regexp.MustCompile("^\\s*([a-zA-Z_][a-zA-Z0-9_]*)\\s*(\\((.*)\\))?\\s*$")
+
+// tagFunc function call info of struct tag
+type tagFunc struct {
+	Name            string
+	Params          []string
+	callFunc        CallFunc        //resolved registered function
+	callFuncContext CallFuncContext //resolved registered context function
+	valueFunc       ValueFunc       //resolved registered value function
+}
 
 // tagTokenizer struct tag info
+//	selector->fn1()->fn2(xxx)->...
+//	selector1->fn1() || selector2->fn2() || ...
 type tagTokenizer struct {
-	Selector   string
-	FuncName   string
-	FuncParams []string
+	Value     string `json:"-"` //raw tag value
+	Selector  string
+	Funcs     []*tagFunc
+	Fallbacks []*tagTokenizer `json:",omitempty"` //alternatives are used in order if the result is empty
+	xpathExpr *xpath.Expr     //compiled selector if selector has `xpath:` prefix
+	matcher   goquery.Matcher //compiled css selector, nil if selector is invalid
+}
+
+// find the nodes of tag selector from selection, returns selection if selector is empty
+func (tag *tagTokenizer) find(selection *goquery.Selection) (*goquery.Selection, error) {
+	if tag.xpathExpr != nil {
+		return findXPath(selection, tag.xpathExpr), nil
+	}
+	if tag.matcher != nil {
+		return selection.FindMatcher(tag.matcher), nil
+	}
+	if tag.Selector != "" {
+		return selection.Find(tag.Selector), nil
+	}
+	return selection, nil
+}
+
+// funcsString returns the functions pipeline, eg: `attr(href)->trim()`
+func (tag *tagTokenizer) funcsString() string {
+	list := make([]string, len(tag.Funcs))
+	for i, fn := range tag.Funcs {
+		list[i] = fn.String()
+	}
+	return strings.Join(list, "->")
+}
+
+// String returns the function call, eg: `attr(href)`
+func (fn *tagFunc) String() string {
+	return fmt.Sprintf("%v(%v)", fn.Name, strings.Join(fn.Params, ", "))
 }
 
 func (p *Pagser) newTag(tagValue string) (*tagTokenizer, error) {
 	//fmt.Println("tag value: ", tagValue)
-	tag := &tagTokenizer{}
+	tag := &tagTokenizer{Value: tagValue}
 	if tagValue == "" {
 		return tag, nil
 	}
-	selectors := strings.Split(tagValue, p.Config.FuncSymbol)
-	funcValue := ""
-	for i := 0; i < len(selectors); i++ {
-		switch i {
-		case 0:
-			tag.Selector = strings.TrimSpace(selectors[i])
-		case 1:
-			funcValue = selectors[i]
+	if alternatives := splitFallbacks(tagValue); len(alternatives) > 1 {
+		for i, alternative := range alternatives {
+			if strings.TrimSpace(alternative) == "" {
+				return nil, fmt.Errorf("tag=`%v` is invalid: alternative %v is empty", tagValue, i+1)
+			}
+			altTag, err := p.newTag(alternative)
+			if err != nil {
+				return nil, err
+			}
+			if i == 0 {
+				tag = altTag
+			} else {
+				tag.Fallbacks = append(tag.Fallbacks, altTag)
+			}
 		}
-	}
-	matches := rxFunc.FindStringSubmatch(funcValue)
-	if len(matches) < 3 {
+		tag.Value = tagValue
 		return tag, nil
 	}
-	tag.FuncName = strings.TrimSpace(matches[1])
-	//tag.FuncParams = strings.Split(matches[2], ",")
-	params, err := parseFuncParamTokens(matches[3])
+	segments := splitUnquoted(tagValue, p.Config.FuncSymbol)
+	tag.Selector = strings.TrimSpace(segments[0])
+	xpathExpr, err := compileXPath(tag.Selector)
 	if err != nil {
 		return nil, fmt.Errorf("tag=`%v` is invalid: %v", tagValue, err)
 	}
-	tag.FuncParams = params
+	tag.xpathExpr = xpathExpr
+	if xpathExpr == nil && tag.Selector != "" {
+		if matcher, err := cascadia.Compile(tag.Selector); err == nil {
+			tag.matcher = matcher
+		}
+	}
+	for _, funcValue := range segments[1:] {
+		matches := rxFunc.FindStringSubmatch(funcValue)
+		if len(matches) < 4 {
+			return nil, fmt.Errorf("tag=`%v` is invalid: function `%v` syntax error", tagValue, strings.TrimSpace(funcValue))
+		}
+		//tag.FuncParams = strings.Split(matches[2], ",")
+		params, err := parseFuncParamTokens(matches[3])
+		if err != nil {
+			return nil, fmt.Errorf("tag=`%v` is invalid: %v", tagValue, err)
+		}
+		fn := &tagFunc{
+			Name:   strings.TrimSpace(matches[1]),
+			Params: params,
+		}
+		if cfn, ok := p.mapFuncs.Load(fn.Name); ok {
+			switch cfn := cfn.(type) {
+			case CallFunc:
+				fn.callFunc = cfn
+			case CallFuncContext:
+				fn.callFuncContext = cfn
+			}
+		}
+		if vfn, ok := p.mapValueFuncs.Load(fn.Name); ok {
+			fn.valueFunc = vfn.(ValueFunc)
+		}
+		tag.Funcs = append(tag.Funcs, fn)
+	}
 	if p.Config.Debug {
 		fmt.Printf("----- debug -----\n`%v`\n%v\n", tagValue, prettyJson(tag))
 	}
 	return tag, nil
 }
 
+// splitFallbacks split tag value by fallback symbol `||` outside of quotes
+func splitFallbacks(tagValue string) []string {
+	return splitUnquoted(tagValue, fallbackSymbol)
+}
+
+// splitUnquoted split tag value by symbol outside of quotes, eg: the function symbol in `replace('->', '')` is not split
+func splitUnquoted(tagValue string, symbol string) []string {
+	segments := make([]string, 0)
+	var quote byte
+	start := 0
+	for pos := 0; pos < len(tagValue); pos++ {
+		ch := tagValue[pos]
+		switch {
+		case ch == '\\' && quote != 0:
+			//skip escape character
+			pos++
+		case ch == '\'' || ch == '"':
+			if quote == 0 {
+				quote = ch
+			} else if quote == ch {
+				quote = 0
+			}
+		case quote == 0 && strings.HasPrefix(tagValue[pos:], symbol):
+			segments = append(segments, tagValue[start:pos])
+			pos += len(symbol) - 1
+			start = pos + 1
+		}
+	}
+	return append(segments, tagValue[start:])
+}
+
 func parseFuncParamTokens(text string) ([]string, error) {
 	tokens := make([]string, 0)
 	textLen := len(text)
@@ -38,3 +38,69 @@ func TestFuncParamTokens(t *testing.T) {
 
 	}
 }
+
+func TestNewTagPipeline(t *testing.T) {
+	p := New()
+	tag, err := p.newTag("a->eq(0)->attr(href)->trim('/')")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag.Selector != "a" {
+		t.Fatalf("selector want `a`, but got `%v`", tag.Selector)
+	}
+	names := []string{"eq", "attr", "trim"}
+	if len(tag.Funcs) != len(names) {
+		t.Fatalf("funcs want %v, but got %v", names, prettyJson(tag.Funcs))
+	}
+	for i, name := range names {
+		if tag.Funcs[i].Name != name {
+			t.Errorf("func[%v] want `%v`, but got `%v`", i, name, tag.Funcs[i].Name)
+		}
+	}
+	if tag.Funcs[2].Params[0] != "/" {
+		t.Errorf("trim param want `/`, but got %v", tag.Funcs[2].Params)
+	}
+
+	tag, err = p.newTag("a[title='a->b']->text()->replace('->', '')")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag.Selector != "a[title='a->b']" || len(tag.Funcs) != 2 || tag.Funcs[1].Params[0] != "->" {
+		t.Errorf("function symbol in quotes want not split, but got %v", prettyJson(tag))
+	}
+
+	for _, v := range []string{"a->", "a->text()->", "a->1abc()", "a->attr('href)"} {
+		if _, err := p.newTag(v); err == nil {
+			t.Errorf("tag `%v` want an error", v)
+		}
+	}
+}
+
+func TestSplitFallbacks(t *testing.T) {
+	tests := map[string][]string{
+		`h1`:                             {`h1`},
+		`h1 || h2`:                       {`h1 `, ` h2`},
+		`h1->text() || .name || meta`:    {`h1->text() `, ` .name `, ` meta`},
+		`h1->textSplit('||') || h2`:      {`h1->textSplit('||') `, ` h2`},
+		`[title="a||b"] || h2`:           {`[title="a||b"] `, ` h2`},
+		`h1->textEmpty('it\'s||') || h2`: {`h1->textEmpty('it\'s||') `, ` h2`},
+	}
+	for input, want := range tests {
+		got := splitFallbacks(input)
+		if prettyJson(got) != prettyJson(want) {
+			t.Errorf("splitFallbacks(%v) want %v, but got %v", input, want, got)
+		}
+	}
+
+	p := New()
+	tag, err := p.newTag("h1.title || .product-name->text() || meta[property=og:title]->attr(content)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag.Selector != "h1.title" || len(tag.Fallbacks) != 2 || tag.Fallbacks[1].Funcs[0].Name != "attr" {
+		t.Fatalf("unexpected tag: %v", prettyJson(tag))
+	}
+	if _, err := p.newTag("h1 || "); err == nil {
+		t.Error("empty alternative must return error")
+	}
+}
@@ -0,0 +1,243 @@
+package pagser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// maxTraceNodes the maximum node texts of a Selection output in trace
+const maxTraceNodes = 10
+
+// Trace the structured trace of parsing, it can be dumped as JSON
+type Trace struct {
+	Fields   []*FieldTrace `json:"fields"`   //fields in parsing order, the nested fields follow the parent field
+	Duration time.Duration `json:"duration"` //total time, nanoseconds in JSON
+}
+
+// FieldTrace the trace of a field
+type FieldTrace struct {
+	Path     string        `json:"path"`            //field path, eg: `Items[3].Name`
+	Tag      string        `json:"tag"`             //tag value
+	Selector string        `json:"selector"`        //selector of the used alternative of tag and fallbacks
+	Matched  int           `json:"matched"`         //number of nodes matched by selector
+	Funcs    []*FuncTrace  `json:"funcs,omitempty"` //functions called in order
+	Output   interface{}   `json:"output"`          //raw output of the last function, or the matched nodes
+	Value    interface{}   `json:"value,omitempty"` //converted field value, nil for nested struct fields
+	Error    string        `json:"error,omitempty"` //field error
+	Duration time.Duration `json:"duration"`        //time spent, including nested fields, nanoseconds in JSON
+
+	start  time.Time
+	sealed bool //the tag of field is executed, the selections and functions of map keys and values are not traced
+}
+
+// FuncTrace the trace of a function call
+type FuncTrace struct {
+	Name   string      `json:"name"`
+	Args   []string    `json:"args"`
+	Output interface{} `json:"output"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// TraceNodes the JSON value of Selection output, see OutputJson
+type TraceNodes struct {
+	Size int      `json:"size"`
+	Text []string `json:"text"` //trimmed text of the first 10 nodes
+}
+
+// ParseWithTrace parse html to struct, and returns the trace of each field with the parse error.
+// The ParsePagser method of Parser is not used, the fields are traced by reflection.
+//	trace, err := p.ParseWithTrace(&data, html)
+//	json.NewEncoder(os.Stdout).Encode(trace)
+func (p *Pagser) ParseWithTrace(v interface{}, document string) (*Trace, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(document))
+	if err != nil {
+		return nil, err
+	}
+	return p.ParseSelectionWithTrace(v, doc.Selection)
+}
+
+// ParseSelectionWithTrace parse selection to struct with trace, see ParseWithTrace
+func (p *Pagser) ParseSelectionWithTrace(v interface{}, selection *goquery.Selection) (*Trace, error) {
+	return p.ParseSelectionWithTraceContext(context.Background(), v, selection)
+}
+
+// ParseSelectionWithTraceContext parse selection to struct with trace and context, see ParseWithTrace
+func (p *Pagser) ParseSelectionWithTraceContext(ctx context.Context, v interface{}, selection *goquery.Selection) (*Trace, error) {
+	start := time.Now()
+	t := &tracer{pagser: p, trace: &Trace{Fields: []*FieldTrace{}}}
+	defer func() {
+		t.trace.Duration = time.Since(start)
+	}()
+	if err := ctx.Err(); err != nil {
+		return t.trace, err
+	}
+	state := p.newParseState(withDocumentBaseURL(ctx, selection))
+	state.tracer = t
+	state.onSelect = t.selected
+	state.onFunc = t.called
+	if err := p.doParse(state, v, nil, selection, ""); err != nil {
+		return t.trace, err
+	}
+	if len(state.errs) > 0 {
+		return t.trace, state.errs
+	}
+	return t.trace, nil
+}
+
+// tracer records the field traces, the methods of nil tracer do nothing
+type tracer struct {
+	pagser *Pagser
+	trace  *Trace
+	stack  []*FieldTrace //fields are parsing
+}
+
+// fork returns the tracer of a slice item parsed by another goroutine
+func (t *tracer) fork() *tracer {
+	return &tracer{pagser: t.pagser, trace: &Trace{Fields: []*FieldTrace{}}}
+}
+
+// merge the field traces of item tracer
+func (t *tracer) merge(item *tracer) {
+	if t == nil || item == nil {
+		return
+	}
+	t.trace.Fields = append(t.trace.Fields, item.trace.Fields...)
+}
+
+// begin the field, it is the current field until end
+func (t *tracer) begin(path string, tag *tagTokenizer) *FieldTrace {
+	if t == nil {
+		return nil
+	}
+	field := &FieldTrace{Path: path, Tag: tag.Value, Selector: tag.Selector, start: time.Now()}
+	t.trace.Fields = append(t.trace.Fields, field)
+	t.stack = append(t.stack, field)
+	return field
+}
+
+// end the field with field value and error
+func (t *tracer) end(field *FieldTrace, fieldValue reflect.Value, err error) {
+	if t == nil {
+		return
+	}
+	t.stack = t.stack[:len(t.stack)-1]
+	field.Duration = time.Since(field.start)
+	if err != nil {
+		field.Error = err.Error()
+		return
+	}
+	if fieldValue.IsValid() && fieldValue.CanInterface() && t.pagser.isTracedValueType(fieldValue.Type()) {
+		field.Value = OutputJson(fieldValue.Interface())
+	}
+}
+
+// output set the tag output of current field, the later selections and functions are not of the tag
+func (t *tracer) output(out interface{}, matched int) {
+	if t == nil || len(t.stack) == 0 {
+		return
+	}
+	field := t.stack[len(t.stack)-1]
+	field.Output = OutputJson(out)
+	field.Matched = matched
+	field.sealed = true
+}
+
+// selected records the alternative of current field, the functions of previous alternative are replaced
+func (t *tracer) selected(tag *tagTokenizer, node *goquery.Selection) {
+	if field := t.current(); field != nil {
+		field.Selector = tag.Selector
+		field.Matched = node.Size()
+		field.Funcs = nil
+	}
+}
+
+// called records the function call of current field
+func (t *tracer) called(fn *tagFunc, out interface{}, err error) {
+	if field := t.current(); field != nil {
+		call := &FuncTrace{Name: fn.Name, Args: fn.Params, Output: OutputJson(out)}
+		if call.Args == nil {
+			call.Args = []string{}
+		}
+		if err != nil {
+			call.Error = err.Error()
+		}
+		field.Funcs = append(field.Funcs, call)
+	}
+}
+
+// current returns the current field if its tag is executing
+func (t *tracer) current() *FieldTrace {
+	if len(t.stack) == 0 || t.stack[len(t.stack)-1].sealed {
+		return nil
+	}
+	return t.stack[len(t.stack)-1]
+}
+
+// isTracedValueType the value of type is traced, the nested structs are traced by their fields
+func (p *Pagser) isTracedValueType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		t = t.Elem()
+	}
+	return p.nestedStructType(t) == nil
+}
+
+// OutputJson returns the JSON value of output, it is used by trace, playground and command line to render outputs
+// in the same way. Selection is TraceNodes, json.Marshaler like time.Time is kept, error and fmt.Stringer like *url.URL are string, slices are []interface{},
+// maps are map[string]interface{}, and the values can not be JSON are formatted.
+func OutputJson(out interface{}) interface{} {
+	switch v := out.(type) {
+	case nil, string, bool, int, int64, float64:
+		return v
+	case *goquery.Selection:
+		nodes := &TraceNodes{Size: v.Size(), Text: []string{}}
+		v.EachWithBreak(func(i int, node *goquery.Selection) bool {
+			nodes.Text = append(nodes.Text, strings.TrimSpace(node.Text()))
+			return len(nodes.Text) < maxTraceNodes
+		})
+		return nodes
+	case error:
+		return v.Error()
+	case json.Marshaler:
+		return v
+	case fmt.Stringer:
+		if refValue := reflect.ValueOf(v); refValue.Kind() == reflect.Ptr && refValue.IsNil() {
+			return nil
+		}
+		return v.String()
+	}
+	refValue := reflect.ValueOf(out)
+	switch refValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		if refValue.Kind() == reflect.Slice && refValue.Type().Elem().Kind() == reflect.Uint8 {
+			return string(refValue.Bytes())
+		}
+		list := make([]interface{}, refValue.Len())
+		for i := range list {
+			list[i] = OutputJson(refValue.Index(i).Interface())
+		}
+		return list
+	case reflect.Map:
+		m := make(map[string]interface{}, refValue.Len())
+		iter := refValue.MapRange()
+		for iter.Next() {
+			m[fmt.Sprint(iter.Key().Interface())] = OutputJson(iter.Value().Interface())
+		}
+		return m
+	case reflect.Ptr:
+		if refValue.IsNil() {
+			return nil
+		}
+		return OutputJson(refValue.Elem().Interface())
+	}
+	if _, err := json.Marshal(out); err != nil {
+		return fmt.Sprint(out)
+	}
+	return out
+}
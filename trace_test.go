@@ -0,0 +1,126 @@
+package pagser
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const rawTraceHtml = `<html><head><title>Trace</title></head><body>
+<h1> Pagser </h1>
+<a class="repo" href="https://github.com/foolin/pagser">GitHub</a>
+<span class="count">12</span>
+<ul><li><a href="/a">A</a></li><li><a href="/b">B</a></li></ul>
+</body></html>`
+
+type TraceItem struct {
+	Name string `pagser:"a"`
+	Link string `pagser:"a->attr(href)"`
+}
+
+type TraceData struct {
+	Title string      `pagser:".missing || h1->text()->toUpper()"`
+	Repo  string      `pagser:"a.repo->attr(href)->trimPrefix('https://')"`
+	Count int         `pagser:".count"`
+	Items []TraceItem `pagser:"li" pagser_opts:"parallel=2"`
+	Stars int         `pagser:".stars" pagser_opts:"default=3"`
+	Bad   string      `pagser:"h1->nope()"`
+}
+
+func TestParseWithTrace(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ContinueOnError = true
+	p, err := NewWithConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var data TraceData
+	trace, err := p.ParseWithTrace(&data, rawTraceHtml)
+	var errs ParseErrors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Path != "Bad" {
+		t.Fatalf("want error of Bad, but got %v", err)
+	}
+	if data.Title != "PAGSER" || len(data.Items) != 2 || data.Stars != 3 {
+		t.Fatalf("unexpected data: %v", prettyJson(data))
+	}
+
+	paths := make([]string, len(trace.Fields))
+	fields := make(map[string]*FieldTrace)
+	for i, field := range trace.Fields {
+		paths[i] = field.Path
+		fields[field.Path] = field
+	}
+	if strings.Join(paths, ",") != "Title,Repo,Count,Items,Items[0].Name,Items[0].Link,Items[1].Name,Items[1].Link,Stars,Bad" {
+		t.Fatalf("unexpected paths: %v", paths)
+	}
+
+	title := fields["Title"]
+	if title.Selector != "h1" || title.Matched != 1 || len(title.Funcs) != 2 || title.Output != "PAGSER" || title.Value != "PAGSER" {
+		t.Fatalf("unexpected trace of Title: %v", prettyJson(title))
+	}
+	if fn := title.Funcs[0]; fn.Name != "text" || len(fn.Args) != 0 || fn.Output != "Pagser" {
+		t.Fatalf("unexpected func trace: %v", prettyJson(fn))
+	}
+	if repo := fields["Repo"]; repo.Funcs[1].Name != "trimPrefix" || repo.Funcs[1].Args[0] != "https://" || repo.Value != "github.com/foolin/pagser" {
+		t.Fatalf("unexpected trace of Repo: %v", prettyJson(repo))
+	}
+	if count := fields["Count"]; count.Value != 12 || count.Output.(*TraceNodes).Text[0] != "12" {
+		t.Fatalf("unexpected trace of Count: %v", prettyJson(count))
+	}
+	//the items are parsed by goroutines of parallel option
+	if link := fields["Items[1].Link"]; link.Value != "/b" || len(link.Funcs) != 1 || link.Funcs[0].Name != "attr" {
+		t.Fatalf("unexpected trace of Items[1].Link: %v", prettyJson(link))
+	}
+	if items := fields["Items"]; items.Matched != 2 || items.Value != nil {
+		t.Fatalf("unexpected trace of Items: %v", prettyJson(items))
+	}
+	if stars := fields["Stars"]; stars.Matched != 0 || stars.Value != 3 {
+		t.Fatalf("unexpected trace of Stars: %v", prettyJson(stars))
+	}
+	if bad := fields["Bad"]; bad.Error == "" || len(bad.Funcs) != 1 || bad.Funcs[0].Error == "" {
+		t.Fatalf("unexpected trace of Bad: %v", prettyJson(bad))
+	}
+	if trace.Duration <= 0 || fields["Items"].Duration < fields["Items[0].Name"].Duration {
+		t.Fatalf("unexpected durations: %v", prettyJson(trace))
+	}
+
+	data2, err := json.Marshal(trace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data2), `"output":{"size":1,"text":["12"]}`) {
+		t.Fatalf("unexpected json: %s", data2)
+	}
+}
+
+func TestOutputJson(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(rawTraceHtml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse("https://example.com/a")
+	var nilURL *url.URL
+	date := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	out := OutputJson(map[string]interface{}{
+		"nodes": doc.Find("li"),
+		"url":   u,
+		"nil":   nilURL,
+		"time":  date,
+		"list":  []*url.URL{u},
+		"err":   errors.New("bad"),
+	})
+	data, err := json.Marshal(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"err":"bad","list":["https://example.com/a"],"nil":null,"nodes":{"size":2,"text":["A","B"]},` +
+		`"time":"2020-01-02T00:00:00Z","url":"https://example.com/a"}`
+	if string(data) != want {
+		t.Errorf("want %v, but got %s", want, data)
+	}
+}
@@ -0,0 +1,91 @@
+package pagser
+
+import (
+	"encoding"
+	"reflect"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Unmarshaler is the interface implemented by types that can unmarshal themselves from a Selection,
+// the field selection (after tag functions) is passed to UnmarshalSelection.
+//
+//	type Money struct {
+//		Amount   float64
+//		Currency string
+//	}
+//
+//	func (m *Money) UnmarshalSelection(node *goquery.Selection) error {
+//		m.Currency = node.Find(".currency").Text()
+//		m.Amount = cast.ToFloat64(node.Find(".amount").Text())
+//		return nil
+//	}
+//
+//	type PageData struct{
+//	     Price Money `pagser:".price"`
+//	}
+//
+// Types implement encoding.TextUnmarshaler are unmarshaled from the trimmed text, or the function output.
+type Unmarshaler interface {
+	UnmarshalSelection(node *goquery.Selection) error
+}
+
+var (
+	unmarshalerType     = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// isUnmarshaler the type or pointer to the type implements Unmarshaler
+func isUnmarshaler(t reflect.Type) bool {
+	return implementsOrPtr(t, unmarshalerType)
+}
+
+// isTextUnmarshaler the type or pointer to the type implements encoding.TextUnmarshaler
+func isTextUnmarshaler(t reflect.Type) bool {
+	return implementsOrPtr(t, textUnmarshalerType)
+}
+
+// hasTaggedFields the struct or pointer to struct has fields with tag
+func hasTaggedFields(t reflect.Type, tagName string) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if _, ok := t.Field(i).Tag.Lookup(tagName); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func implementsOrPtr(t reflect.Type, interfaceType reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		return t.Implements(interfaceType)
+	}
+	return reflect.PtrTo(t).Implements(interfaceType)
+}
+
+// unmarshalerOf returns the pointer of field value to call the unmarshal method,
+// it allocates a new value if field is a nil pointer.
+func unmarshalerOf(fieldValue reflect.Value) interface{} {
+	if fieldValue.Kind() == reflect.Ptr {
+		if fieldValue.IsNil() {
+			fieldValue.Set(reflect.New(fieldValue.Type().Elem()))
+		}
+		return fieldValue.Interface()
+	}
+	return fieldValue.Addr().Interface()
+}
+
+// unmarshalSelection call UnmarshalSelection of field value
+func unmarshalSelection(fieldValue reflect.Value, node *goquery.Selection) error {
+	return unmarshalerOf(fieldValue).(Unmarshaler).UnmarshalSelection(node)
+}
+
+// unmarshalText call UnmarshalText of field value
+func unmarshalText(fieldValue reflect.Value, text string) error {
+	return unmarshalerOf(fieldValue).(encoding.TextUnmarshaler).UnmarshalText([]byte(text))
+}
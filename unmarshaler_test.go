@@ -0,0 +1,144 @@
+package pagser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/spf13/cast"
+)
+
+const rawUnmarshalHtml = `
+<html>
+<body>
+	<div class="price"><span class="amount">19.99</span><span class="currency">USD</span></div>
+	<ul class="prices">
+		<li><span class="amount">1.5</span><span class="currency">EUR</span></li>
+		<li><span class="amount">2.5</span><span class="currency">GBP</span></li>
+	</ul>
+	<span class="isbn">978-3-16-148410-0</span>
+	<span class="rating" data-value="4/5">Good</span>
+	<span class="bad">bad-isbn</span>
+</body>
+</html>
+`
+
+type Money struct {
+	Amount   float64
+	Currency string
+}
+
+func (m *Money) UnmarshalSelection(node *goquery.Selection) error {
+	if node.Size() == 0 {
+		return errors.New("money not found")
+	}
+	m.Amount = cast.ToFloat64(node.Find(".amount").Text())
+	m.Currency = node.Find(".currency").Text()
+	return nil
+}
+
+type ISBN string
+
+func (i *ISBN) UnmarshalText(text []byte) error {
+	value := strings.ReplaceAll(string(text), "-", "")
+	if len(value) != 13 {
+		return fmt.Errorf("invalid isbn: %v", string(text))
+	}
+	*i = ISBN(value)
+	return nil
+}
+
+type Rating struct {
+	Score int
+	Max   int
+}
+
+func (r *Rating) UnmarshalText(text []byte) error {
+	_, err := fmt.Sscanf(string(text), "%d/%d", &r.Score, &r.Max)
+	return err
+}
+
+type UnmarshalData struct {
+	Price      Money            `pagser:".price"`
+	PricePtr   *Money           `pagser:".price"`
+	Prices     []Money          `pagser:".prices li"`
+	PricePtrs  []*Money         `pagser:".prices li"`
+	PriceMap   map[string]Money `pagser:".prices li" pagser_key:".currency"`
+	PriceEq    Money            `pagser:".prices li->eq(1)"`
+	ISBN       ISBN             `pagser:".isbn"`
+	ISBNPtr    *ISBN            `pagser:".isbn"`
+	Rating     Rating           `pagser:".rating->attr(data-value)"`
+	ISBNList   []ISBN           `pagser:".isbn->eachText()"`
+	RatingList []Rating         `pagser:".rating->eachAttr(data-value)"`
+}
+
+func TestParseUnmarshaler(t *testing.T) {
+	p := New()
+
+	var data UnmarshalData
+	err := p.Parse(&data, rawUnmarshalHtml)
+	if err != nil {
+		t.Fatal(err)
+	}
+	isbn := ISBN("9783161484100")
+	want := UnmarshalData{
+		Price:      Money{19.99, "USD"},
+		PricePtr:   &Money{19.99, "USD"},
+		Prices:     []Money{{1.5, "EUR"}, {2.5, "GBP"}},
+		PricePtrs:  []*Money{{1.5, "EUR"}, {2.5, "GBP"}},
+		PriceMap:   map[string]Money{"EUR": {1.5, "EUR"}, "GBP": {2.5, "GBP"}},
+		PriceEq:    Money{2.5, "GBP"},
+		ISBN:       isbn,
+		ISBNPtr:    &isbn,
+		Rating:     Rating{4, 5},
+		ISBNList:   []ISBN{isbn},
+		RatingList: []Rating{{4, 5}},
+	}
+	if prettyJson(data) != prettyJson(want) {
+		t.Fatalf("want %v, but got %v", prettyJson(want), prettyJson(data))
+	}
+
+	var notFound struct {
+		Price Money `pagser:".not-found"`
+	}
+	if err := p.Parse(&notFound, rawUnmarshalHtml); err == nil {
+		t.Error("UnmarshalSelection error must be returned")
+	}
+
+	var badData struct {
+		BadISBN ISBN `pagser:".bad"`
+	}
+	if err := p.Parse(&badData, rawUnmarshalHtml); err == nil {
+		t.Error("UnmarshalText error must be returned")
+	}
+}
+
+type TaggedRating struct {
+	Text  string `pagser:"->text()"`
+	Value string `pagser:"->attr(data-value)"`
+}
+
+func (r *TaggedRating) UnmarshalText(text []byte) error {
+	r.Text = string(text)
+	return nil
+}
+
+func TestParseTaggedTextUnmarshaler(t *testing.T) {
+	var data struct {
+		Rating     TaggedRating  `pagser:".rating"`
+		RatingPtr  *TaggedRating `pagser:".rating"`
+		RatingText TaggedRating  `pagser:".rating->attr(data-value)"`
+	}
+	if err := New().Parse(&data, rawUnmarshalHtml); err != nil {
+		t.Fatal(err)
+	}
+	want := TaggedRating{Text: "Good", Value: "4/5"}
+	if data.Rating != want || data.RatingPtr == nil || *data.RatingPtr != want {
+		t.Errorf("tagged fields must be parsed, want %v, but got %v", want, prettyJson(data))
+	}
+	if data.RatingText != (TaggedRating{Text: "4/5"}) {
+		t.Errorf("function output must be unmarshaled, but got %v", data.RatingText)
+	}
+}
@@ -0,0 +1,63 @@
+package pagser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xpath"
+	"golang.org/x/net/html"
+)
+
+// xpathPrefix selector prefix for XPath expression, eg: `pagser:"xpath://h1[contains(text(), 'Pagser')]->text()"`
+const xpathPrefix = "xpath:"
+
+// compileXPath compile the selector if it's XPath expression, returns nil if not
+func compileXPath(selector string) (*xpath.Expr, error) {
+	if !strings.HasPrefix(selector, xpathPrefix) {
+		return nil, nil
+	}
+	expr, err := xpath.Compile(strings.TrimSpace(strings.TrimPrefix(selector, xpathPrefix)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid xpath `%v`: %v", selector, err)
+	}
+	//the expression is evaluated once before it is shared, the type of result does not depend on the document
+	result := expr.Evaluate(htmlquery.CreateXPathNavigator(&html.Node{Type: html.DocumentNode}))
+	if _, ok := result.(*xpath.NodeIterator); !ok {
+		return nil, fmt.Errorf("xpath `%v` must select nodes, but got %T value", selector, result)
+	}
+	return expr, nil
+}
+
+// findXPath select nodes by XPath expression with each node of selection as context node,
+// it returns a Selection containing the matched nodes of the same document.
+// The expression is shared by parsing goroutines, Select clones the query of expression for each call.
+func findXPath(selection *goquery.Selection, expr *xpath.Expr) *goquery.Selection {
+	nodes := make([]*html.Node, 0)
+	for _, top := range selection.Nodes {
+		iter := expr.Select(htmlquery.CreateXPathNavigator(top))
+		for iter.MoveNext() {
+			nodes = append(nodes, xpathNode(iter.Current().(*htmlquery.NodeNavigator)))
+		}
+	}
+	return selection.FilterNodes().AddNodes(nodes...)
+}
+
+// xpathNode returns the current node of navigator, an attribute is returned as
+// element node with attribute name and value text, eg: `//a/@href->text()`
+func xpathNode(nav *htmlquery.NodeNavigator) *html.Node {
+	if nav.NodeType() != xpath.AttributeNode {
+		return nav.Current()
+	}
+	textNode := &html.Node{
+		Type: html.TextNode,
+		Data: nav.Value(),
+	}
+	return &html.Node{
+		Type:       html.ElementNode,
+		Data:       nav.LocalName(),
+		FirstChild: textNode,
+		LastChild:  textNode,
+	}
+}
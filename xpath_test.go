@@ -0,0 +1,89 @@
+package pagser
+
+import (
+	"sync"
+	"testing"
+)
+
+type XPathData struct {
+	Title     string   `pagser:"xpath://title"`
+	H2ByText  string   `pagser:"xpath://h2[text()='Bool']/following-sibling::ul/li[1]->attr(value)"`
+	NavHrefs  []string `pagser:"xpath://div[@class='navlink']//a/@href->eachText()"`
+	NavTitles []string `pagser:"xpath://a[@title]->eachAttr(title)"`
+	Groups    []struct {
+		Name  string   `pagser:"xpath:./h2"`
+		Items []string `pagser:"xpath:.//li[@class='item']"`
+	} `pagser:"xpath://div[@class='group']"`
+	XPathFirst string `pagser:"xpath://li[@name='float']->last()->attr(value)"`
+	Empty      string `pagser:"xpath://not-exists->textEmpty('none')"`
+	Mixed      struct {
+		Link string `pagser:"xpath:.//a[contains(text(), 'Mobile')]->attr(href)"`
+	} `pagser:".navlink"`
+}
+
+func TestParseXPath(t *testing.T) {
+	p := New()
+
+	var data XPathData
+	err := p.Parse(&data, rawParseHtml)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.Title != "Pagser Example" {
+		t.Errorf("title want `Pagser Example`, but got `%v`", data.Title)
+	}
+	if data.H2ByText != "true" {
+		t.Errorf("h2 by text want `true`, but got `%v`", data.H2ByText)
+	}
+	if len(data.NavHrefs) != 4 || data.NavHrefs[3] != "/list/mobile" {
+		t.Errorf("nav hrefs want 4 items, but got %v", data.NavHrefs)
+	}
+	if len(data.NavTitles) != 3 {
+		t.Errorf("nav titles want 3 items, but got %v", data.NavTitles)
+	}
+	if len(data.Groups) != 4 || data.Groups[2].Name != "Number" || len(data.Groups[2].Items) != 2 {
+		t.Errorf("groups want 4 items, but got %v", prettyJson(data.Groups))
+	}
+	if data.XPathFirst != "678.90" {
+		t.Errorf("xpath first want `678.90`, but got `%v`", data.XPathFirst)
+	}
+	if data.Empty != "none" {
+		t.Errorf("empty want `none`, but got `%v`", data.Empty)
+	}
+	if data.Mixed.Link != "/list/mobile" {
+		t.Errorf("mixed link want `/list/mobile`, but got `%v`", data.Mixed.Link)
+	}
+
+	var invalidData struct {
+		Value string `pagser:"xpath://div[@class="`
+	}
+	if err := p.Parse(&invalidData, rawParseHtml); err == nil {
+		t.Error("invalid xpath must return error")
+	}
+	var countData struct {
+		Value string `pagser:"xpath:count(//li)"`
+	}
+	if err := p.Parse(&countData, rawParseHtml); err == nil {
+		t.Error("xpath not select nodes must return error")
+	}
+}
+
+func TestParseXPathConcurrent(t *testing.T) {
+	p := New()
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var data XPathData
+			if err := p.Parse(&data, rawParseHtml); err != nil {
+				t.Error(err)
+				return
+			}
+			if len(data.NavHrefs) != 4 || len(data.Groups) != 4 {
+				t.Errorf("nav hrefs and groups want 4 items, but got %v", prettyJson(data))
+			}
+		}()
+	}
+	wg.Wait()
+}